@@ -0,0 +1,134 @@
+package lifecycle
+
+import (
+	"context"
+	"reflect"
+	"runtime"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+var _ types.Group = (*group)(nil)
+
+// group tracks goroutines of the service started with types.Group,
+// new group is created on each service startup.
+type group struct {
+	e      *ServiceEntry
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mx      sync.Mutex
+	closed  bool
+	next    int
+	running map[int]string
+	wg      sync.WaitGroup
+}
+
+func newGroup(e *ServiceEntry) *group {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = types.WithServiceName(ctx, e.cfg.Name)
+	ctx = types.WithReporter(ctx, e.reporter)
+	g := &group{e: e, cancel: cancel, running: make(map[int]string)}
+	g.ctx = types.WithGroup(ctx, g)
+	return g
+}
+
+func (g *group) Go(fn func(ctx context.Context) error) {
+	g.GoNamed(funcName(fn), fn)
+}
+
+func (g *group) GoNamed(name string, fn func(ctx context.Context) error) {
+	g.mx.Lock()
+	defer g.mx.Unlock()
+	if g.closed {
+		g.e.logger.Log(logging.LevelWarn, "goroutine is not started, service is stopping",
+			logging.String("goroutine", name))
+		return
+	}
+	id := g.next
+	g.next++
+	g.running[id] = name
+	g.wg.Add(1)
+
+	labels := pprof.Labels("service", g.e.cfg.Name, "goroutine", name)
+	go pprof.Do(g.ctx, labels, func(ctx context.Context) {
+		defer g.wg.Done()
+		defer func() {
+			g.mx.Lock()
+			delete(g.running, id)
+			g.mx.Unlock()
+		}()
+		err := callHook(ctx, fn)
+		if err != nil && ctx.Err() == nil {
+			g.e.reporter.Fail(errors.Wrapf(err, "goroutine %s", name))
+		}
+	})
+}
+
+// stop cancels goroutines of the group and waits until they return
+// or context is done, it returns ErrGoroutineLeak with names of
+// goroutines which are still running.
+func (g *group) stop(ctx context.Context) error {
+	g.close()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+	}
+	g.mx.Lock()
+	names := make([]string, 0, len(g.running))
+	for _, name := range g.running {
+		names = append(names, name)
+	}
+	g.mx.Unlock()
+	sort.Strings(names)
+	g.e.logger.Log(logging.LevelWarn, "service goroutines leaked", logging.Any("goroutines", names))
+	return errors.Wrapf(types.ErrGoroutineLeak, "[%s]", strings.Join(names, ", "))
+}
+
+// close the group for new goroutines and cancel running ones.
+func (g *group) close() {
+	g.mx.Lock()
+	g.closed = true
+	g.mx.Unlock()
+	g.cancel()
+}
+
+// funcName returns name of the function for goroutine labels and leak reports
+// of goroutines started without name.
+func funcName(fn interface{}) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer()); f != nil {
+		return f.Name()
+	}
+	return "unknown"
+}
+
+// resetGroup creates new goroutine group of the service on startup, goroutines
+// of the previous group are cancelled, but restart doesn't wait for them.
+func (e *ServiceEntry) resetGroup() *group {
+	if prev := e.currentGroup(); prev != nil {
+		prev.close()
+	}
+	g := newGroup(e)
+	e.runMx.Lock()
+	e.group = g
+	e.runMx.Unlock()
+	return g
+}
+
+func (e *ServiceEntry) currentGroup() *group {
+	e.runMx.Lock()
+	defer e.runMx.Unlock()
+	return e.group
+}
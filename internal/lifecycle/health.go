@@ -0,0 +1,78 @@
+package lifecycle
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// Reload running service with its reload hook. Failed reload makes
+// the service degraded until the next successful reload.
+func (e *ServiceEntry) Reload(ctx context.Context) error {
+	if e.cfg.ReloadHook == nil {
+		return errors.New("service has no reload hook")
+	}
+	if st := e.State().Status; !st.Up() {
+		return errors.Errorf("can't reload service in %s status", st)
+	}
+	hookCtx, span := e.startSpan(ctx, "service.reload")
+	hookCtx = types.WithServiceName(hookCtx, e.cfg.Name)
+	err := callHook(hookCtx, e.cfg.ReloadHook)
+	span.End(err)
+	if err != nil {
+		atomic.StoreInt32(&e.reloadFailed, 1)
+		e.reporter.Degraded("reload failed: " + err.Error())
+		return errors.Wrap(err, "reload service")
+	}
+	if atomic.CompareAndSwapInt32(&e.reloadFailed, 1, 0) {
+		e.reporter.Recovered()
+	}
+	return nil
+}
+
+// startHealthCheck runs health check of the service periodically in the group
+// of the service. Failed check makes the service degraded with the check error
+// as a reason, the service is recovered by the next successful check.
+func (e *ServiceEntry) startHealthCheck(g *group) {
+	if e.cfg.HealthCheck == nil {
+		return
+	}
+	interval := e.cfg.HealthCheckInterval
+	if interval <= 0 {
+		interval = types.DefaultHealthCheckInterval
+	}
+	g.GoNamed("health-check", func(ctx context.Context) error {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		var reason string
+		for {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-ticker.C:
+			}
+			if !e.State().Status.Up() {
+				// paused or still starting
+				continue
+			}
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			err := callHook(checkCtx, e.cfg.HealthCheck)
+			cancel()
+			switch {
+			case ctx.Err() != nil:
+				return nil
+			case err != nil:
+				if next := "health check failed: " + err.Error(); next != reason {
+					reason = next
+					e.reporter.Degraded(reason)
+				}
+			case reason != "":
+				reason = ""
+				e.reporter.Recovered()
+			}
+		}
+	})
+}
@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+)
+
+// ReportKind is a kind of service runtime report.
+type ReportKind int
+
+const (
+	// ReportIgnored is an ignorable runtime error.
+	ReportIgnored ReportKind = iota
+	// ReportDegraded is a service degradation.
+	ReportDegraded
+	// ReportRecovered is a recovery from degradation.
+	ReportRecovered
+	// ReportReady is a service readiness.
+	ReportReady
+	// ReportHeartbeat is a service heartbeat.
+	ReportHeartbeat
+	// ReportFinished is a return of job or run-style service function,
+	// error is set if function failed.
+	ReportFinished
+)
+
+// Report is a runtime report of the service.
+type Report struct {
+	Kind   ReportKind
+	Time   time.Time
+	Error  error
+	Reason string
+}
+
+var _ types.Reporter = (*reporter)(nil)
+
+// reporter queues service reports without blocking,
+// they are processed by service events loop.
+type reporter struct {
+	e *ServiceEntry
+}
+
+func (r *reporter) Fail(err error) {
+	if err != nil {
+		r.e.queueReport(queuedReport{err: err})
+	}
+}
+
+func (r *reporter) Degraded(reason string) {
+	r.e.queueReport(queuedReport{report: Report{Kind: ReportDegraded, Reason: reason}})
+}
+
+func (r *reporter) Recovered() {
+	r.e.queueReport(queuedReport{report: Report{Kind: ReportRecovered}})
+}
+
+func (r *reporter) Ready() {
+	r.e.queueReport(queuedReport{report: Report{Kind: ReportReady}})
+}
+
+func (r *reporter) Heartbeat() {
+	r.e.queueReport(queuedReport{report: Report{Kind: ReportHeartbeat}})
+}
+
+// queuedReport is either runtime error or status report.
+type queuedReport struct {
+	err    error
+	report Report
+}
+
+func (e *ServiceEntry) queueReport(r queuedReport) {
+	r.report.Time = time.Now()
+	e.reportsMx.Lock()
+	defer e.reportsMx.Unlock()
+	if e.closed {
+		return
+	}
+	e.reports = append(e.reports, r)
+	select {
+	case e.reportsCh <- struct{}{}:
+	default:
+	}
+}
+
+func (e *ServiceEntry) takeReports() []queuedReport {
+	e.reportsMx.Lock()
+	defer e.reportsMx.Unlock()
+	reports := e.reports
+	e.reports = nil
+	return reports
+}
@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"context"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// runFunc returns background function of job or run-style service and its span name.
+func (e *ServiceEntry) runFunc() (func(context.Context) error, string) {
+	if e.cfg.Job != nil {
+		return e.cfg.Job, "service.job"
+	}
+	if e.cfg.Run != nil {
+		return e.cfg.Run, "service.run"
+	}
+	return nil, ""
+}
+
+// startRun runs background function of the service with context derived from
+// group context, its result is reported to events loop. Function context is
+// cancelled when service is stopped.
+func (e *ServiceEntry) startRun(parent context.Context) {
+	fn, name := e.runFunc()
+	if fn == nil {
+		return
+	}
+	ctx, cancel := context.WithCancel(parent)
+	done := make(chan struct{})
+	e.runMx.Lock()
+	e.runCancel = cancel
+	e.runDone = done
+	e.runMx.Unlock()
+
+	ctx, span := e.startSpan(ctx, name)
+	go func() {
+		defer close(done)
+		defer cancel()
+		err := callHook(ctx, fn)
+		span.End(err)
+		e.queueReport(queuedReport{report: Report{Kind: ReportFinished, Error: err}})
+	}()
+}
+
+// stopRun cancels background function and waits until it returns or context is done.
+func (e *ServiceEntry) stopRun(ctx context.Context) error {
+	e.runMx.Lock()
+	cancel, done := e.runCancel, e.runDone
+	e.runMx.Unlock()
+	if cancel == nil {
+		return nil
+	}
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return errors.Wrap(ctx.Err(), "wait for service function")
+	}
+}
+
+// upStatuses are statuses of the service with running background function.
+var upStatuses = []types.ServiceStatus{
+	types.ServiceStatusRunning, types.ServiceStatusReady,
+	types.ServiceStatusDegraded, types.ServiceStatusPaused,
+}
+
+// finishRun handles result of background function returned before service
+// was stopped: job is completed and run-style service is stopped if function
+// succeeded, otherwise service fails and could be restarted.
+func (e *ServiceEntry) finishRun(err error) {
+	if err != nil {
+		state := reportedState(types.ServiceStatusError, upStatuses...)
+		state.Error = err
+		e.push(e.changeContext(context.Background(), false), state)
+		return
+	}
+	if e.cfg.Job != nil {
+		e.push(context.Background(), reportedState(types.ServiceStatusCompleted, types.ServiceStatusRunning))
+		return
+	}
+	e.push(context.Background(), reportedState(types.ServiceStatusStopped, upStatuses...))
+}
@@ -2,12 +2,13 @@ package lifecycle
 
 import (
 	"context"
-	"fmt"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/tracing"
 	"github.com/g4s8/go-lifecycle/pkg/types"
 	"github.com/pkg/errors"
 )
@@ -17,29 +18,123 @@ type stateTransition [2]types.ServiceStatus
 type stateTransitionHandler func(ctx context.Context, service *ServiceEntry, transition stateTransition) error
 
 var stateTransitionsV1 = map[stateTransition]stateTransitionHandler{
-	{types.ServiceStatusInit, types.ServiceStatusStarting}:     onStart,
-	{types.ServiceStatusStarting, types.ServiceStatusStopping}: onStop,
-	{types.ServiceStatusRunning, types.ServiceStatusStopping}:  onStop,
-	{types.ServiceStatusRunning, types.ServiceStatusError}:     onRuntimeError,
-	{types.ServiceStatusStopped, types.ServiceStatusStarting}:  onStart,
-	{types.ServiceStatusError, types.ServiceStatusStarting}:    onStart,
+	{types.ServiceStatusInit, types.ServiceStatusStarting}:      onStart,
+	{types.ServiceStatusStarting, types.ServiceStatusStopping}:  onStop,
+	{types.ServiceStatusRunning, types.ServiceStatusStopping}:   onStop,
+	{types.ServiceStatusRunning, types.ServiceStatusError}:      onRuntimeError,
+	{types.ServiceStatusStarting, types.ServiceStatusRunning}:   onRunning,
+	{types.ServiceStatusReady, types.ServiceStatusStopping}:     onStop,
+	{types.ServiceStatusReady, types.ServiceStatusError}:        onRuntimeError,
+	{types.ServiceStatusDegraded, types.ServiceStatusRunning}:   onRunning,
+	{types.ServiceStatusDegraded, types.ServiceStatusStopping}:  onStop,
+	{types.ServiceStatusDegraded, types.ServiceStatusError}:     onRuntimeError,
+	{types.ServiceStatusRunning, types.ServiceStatusPaused}:     onPause,
+	{types.ServiceStatusReady, types.ServiceStatusPaused}:       onPause,
+	{types.ServiceStatusDegraded, types.ServiceStatusPaused}:    onPause,
+	{types.ServiceStatusPaused, types.ServiceStatusRunning}:     onResume,
+	{types.ServiceStatusPaused, types.ServiceStatusStopping}:    onStop,
+	{types.ServiceStatusPaused, types.ServiceStatusError}:       onRuntimeError,
+	{types.ServiceStatusStopped, types.ServiceStatusStarting}:   onStart,
+	{types.ServiceStatusStopped, types.ServiceStatusStopping}:   onStopExited,
+	{types.ServiceStatusError, types.ServiceStatusStarting}:     onStart,
+	{types.ServiceStatusCompleted, types.ServiceStatusStarting}: onStart,
+	{types.ServiceStatusCompleted, types.ServiceStatusStopping}: onStop,
 }
 
 func onStart(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
 	// INIT -> STARTING
+	service.resetReadiness()
+	atomic.StoreInt32(&service.reloadFailed, 0)
+	if err := service.stopRun(ctx); err != nil {
+		// function of the previous run is still running after runtime error
+		return errors.Wrap(err, "start service")
+	}
+	g := service.resetGroup()
 	if service.cfg.StartupHook != nil {
-		if err := service.cfg.StartupHook(ctx, service.errCh); err != nil {
+		hookCtx, span := service.startSpan(ctx, "service.start")
+		hookCtx = types.WithReporter(hookCtx, service.reporter)
+		hookCtx = types.WithGroup(hookCtx, g)
+		err := callStartupHook(hookCtx, service.cfg.StartupHook, service.errCh)
+		span.End(err)
+		if err != nil {
 			return errors.Wrap(err, "start service")
 		}
 	}
+	service.startRun(g.ctx)
+	service.startHealthCheck(g)
 	service.push(ctx, ServiceState{Status: types.ServiceStatusRunning})
 	return nil
 }
 
+func onRunning(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
+	// STARTING -> RUNNING
+	if service.cfg.Job != nil {
+		// job is not ready but completed
+		return nil
+	}
+	if service.markReady(false) {
+		service.push(ctx, reportedState(types.ServiceStatusReady, types.ServiceStatusRunning))
+	}
+	return nil
+}
+
+func onPause(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
+	// RUNNING -> PAUSED
+	if service.cfg.PauseHook != nil {
+		hookCtx, span := service.startSpan(ctx, "service.pause")
+		err := callHook(hookCtx, service.cfg.PauseHook)
+		span.End(err)
+		if err != nil {
+			return errors.Wrap(err, "pause service")
+		}
+	}
+	return nil
+}
+
+func onResume(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
+	// PAUSED -> RUNNING
+	if service.cfg.ResumeHook != nil {
+		hookCtx, span := service.startSpan(ctx, "service.resume")
+		err := callHook(hookCtx, service.cfg.ResumeHook)
+		span.End(err)
+		if err != nil {
+			return errors.Wrap(err, "resume service")
+		}
+	}
+	return onRunning(ctx, service, transition)
+}
+
 func onStop(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
 	// RUNNING -> STOPPING
+	if err := service.stopRun(ctx); err != nil {
+		return errors.Wrap(err, "stop service")
+	}
+	g := service.currentGroup()
+	if g != nil {
+		g.close()
+	}
 	if service.cfg.ShutdownHook != nil {
-		if err := service.cfg.ShutdownHook(ctx); err != nil {
+		hookCtx, span := service.startSpan(ctx, "service.stop")
+		err := callHook(hookCtx, service.cfg.ShutdownHook)
+		span.End(err)
+		if err != nil {
+			return errors.Wrap(err, "stop service")
+		}
+	}
+	if g != nil {
+		if err := g.stop(ctx); err != nil {
+			return errors.Wrap(err, "stop service")
+		}
+	}
+	service.push(ctx, ServiceState{Status: types.ServiceStatusStopped})
+	return nil
+}
+
+func onStopExited(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
+	// STOPPED -> STOPPING, run function exited cleanly, only goroutines
+	// of the service are stopped, shutdown hook is not called again
+	if g := service.currentGroup(); g != nil {
+		if err := g.stop(ctx); err != nil {
 			return errors.Wrap(err, "stop service")
 		}
 	}
@@ -47,6 +142,24 @@ func onStop(ctx context.Context, service *ServiceEntry, transition stateTransiti
 	return nil
 }
 
+// callStartupHook calls the hook and converts its panic to error.
+func callStartupHook(ctx context.Context, hook types.StartupHook, errCh chan<- error) (err error) {
+	defer recoverPanic(&err)
+	return hook(ctx, errCh)
+}
+
+// callHook calls shutdown, pause or resume hook and converts its panic to error.
+func callHook(ctx context.Context, hook func(context.Context) error) (err error) {
+	defer recoverPanic(&err)
+	return hook(ctx)
+}
+
+func recoverPanic(err *error) {
+	if r := recover(); r != nil {
+		*err = types.NewPanicError(r)
+	}
+}
+
 func onRuntimeError(ctx context.Context, service *ServiceEntry, transition stateTransition) error {
 	restartPol := service.cfg.RestartPolicy
 	if !restartPol.RestartOnFailure {
@@ -55,24 +168,48 @@ func onRuntimeError(ctx context.Context, service *ServiceEntry, transition state
 	if service.restartState == nil {
 		service.restartState = &restartState{}
 	}
+	if service.classify(service.state.Error) == types.ErrorPermanent {
+		service.logger.Log(logging.LevelError, "permanent service error, not restarting",
+			logging.Err(service.state.Error))
+		return service.state.Error
+	}
 	if restartPol.RestartCount > 0 && service.restartState.tryCount >= restartPol.RestartCount {
+		service.logger.Log(logging.LevelWarn, "service restart attempts exhausted",
+			logging.Int("attempts", service.restartState.tryCount), logging.Err(service.state.Error))
 		return service.state.Error
 	}
+	_, span := service.startSpan(ctx, "service.restart")
+	if err := service.state.Error; err != nil {
+		span.SetAttributes(tracing.Attr("cause", err.Error()))
+	}
 	if service.restartState.lastAttempt.IsZero() {
 		service.restartState.lastAttempt = time.Now()
 	} else if interval := time.Since(service.restartState.lastAttempt); restartPol.RestartDelay != 0 &&
 		interval < restartPol.RestartDelay {
-		t := time.NewTimer(service.cfg.RestartPolicy.RestartDelay - interval)
+		delay := service.cfg.RestartPolicy.RestartDelay - interval
+		service.notify(ServiceState{
+			Status:      types.ServiceStatusError,
+			Error:       service.state.Error,
+			NextRestart: time.Now().Add(delay),
+		})
+		span.SetAttributes(tracing.Attr("delay", delay.String()))
+		t := time.NewTimer(delay)
 		select {
 		case <-t.C:
 		case <-ctx.Done():
+			span.End(ctx.Err())
 			return ctx.Err()
 		case <-service.closeCh:
-			return errors.New("service closed")
+			err := errors.New("service closed")
+			span.End(err)
+			return err
 		}
 	}
+	span.End(nil)
 	service.restartState.tryCount++
 	service.restartState.lastAttempt = time.Now()
+	service.logger.Log(logging.LevelInfo, "restarting service",
+		logging.Int("attempt", service.restartState.tryCount), logging.Err(service.state.Error))
 
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -87,6 +224,26 @@ func onRuntimeError(ctx context.Context, service *ServiceEntry, transition state
 type ServiceState struct {
 	Status types.ServiceStatus
 	Error  error
+	// Attempt is a number of restart attempts of the service.
+	Attempt int
+	// NextRestart is a time of the next scheduled restart attempt, if any.
+	NextRestart time.Time
+	// Reason of degraded status.
+	Reason string
+	// Final is true if service failed and it won't be restarted.
+	Final bool
+	// Report is set if service reported its runtime state without
+	// status change, e.g. ignorable error or heartbeat.
+	Report *Report
+
+	// onlyFrom statuses the state could be applied, it's set for states
+	// reported by service, which are ignored if service status was changed.
+	onlyFrom []types.ServiceStatus
+}
+
+// reportedState is a state which could be applied only from one of statuses.
+func reportedState(status types.ServiceStatus, from ...types.ServiceStatus) ServiceState {
+	return ServiceState{Status: status, onlyFrom: from}
 }
 
 const (
@@ -104,8 +261,22 @@ type ServiceEntry struct {
 	cfg             types.ServiceConfig
 	transitionsSpec map[stateTransition]stateTransitionHandler
 	stateCh         chan<- ServiceState
+	logger          logging.Logger
+	tracer          tracing.Tracer
 
 	errCh        chan error
+	reporter     *reporter
+	reports      []queuedReport
+	reportsCh    chan struct{}
+	reportsMx    sync.Mutex
+	closed       bool
+	ready        bool
+	readyMx      sync.Mutex
+	runCancel    context.CancelFunc
+	runDone      chan struct{}
+	runMx        sync.Mutex
+	group        *group
+	reloadFailed int32
 	state        ServiceState
 	stateMx      sync.RWMutex
 	sq           stateQueue
@@ -130,7 +301,7 @@ func newTestServiceEntry(t *testing.T, cfg types.ServiceConfig) *ServiceEntry {
 			}
 		}
 	}()
-	svc := NewServiceEntry(cfg, stateCh)
+	svc := NewServiceEntry(cfg, stateCh, logging.Nop, tracing.Nop)
 	t.Cleanup(func() {
 		svc.Close()
 		close(doneCh)
@@ -139,17 +310,24 @@ func newTestServiceEntry(t *testing.T, cfg types.ServiceConfig) *ServiceEntry {
 	return svc
 }
 
-// NewServiceEntry creates new service entry.
-func NewServiceEntry(cfg types.ServiceConfig, stateCh chan<- ServiceState) *ServiceEntry {
+// NewServiceEntry creates new service entry, logger messages
+// are annotated with service name, tracer is used to trace hooks and restarts.
+func NewServiceEntry(cfg types.ServiceConfig, stateCh chan<- ServiceState,
+	logger logging.Logger, tracer tracing.Tracer,
+) *ServiceEntry {
 	entry := &ServiceEntry{
 		cfg:             cfg,
 		transitionsSpec: stateTransitionsV1,
 		errCh:           make(chan error),
+		reportsCh:       make(chan struct{}, 1),
 		closeCh:         make(chan struct{}, 1),
 		stateCh:         stateCh,
+		logger:          logging.With(logger, logging.String("service", cfg.Name)),
+		tracer:          tracer,
 	}
+	entry.reporter = &reporter{e: entry}
 	entry.doneWg.Add(1)
-	go entry.errorsLoop()
+	go entry.eventsLoop()
 	return entry
 }
 
@@ -163,6 +341,22 @@ func (e *ServiceEntry) Stop(ctx context.Context) error {
 	return e.changeState(ctx, types.ServiceStatusStopping)
 }
 
+// Pause running service.
+func (e *ServiceEntry) Pause(ctx context.Context) error {
+	if st := e.State().Status; !st.Up() {
+		return errors.Errorf("can't pause service in %s status", st)
+	}
+	return e.changeState(ctx, types.ServiceStatusPaused)
+}
+
+// Resume paused service.
+func (e *ServiceEntry) Resume(ctx context.Context) error {
+	if st := e.State().Status; st != types.ServiceStatusPaused {
+		return errors.Errorf("can't resume service in %s status", st)
+	}
+	return e.changeState(ctx, types.ServiceStatusRunning)
+}
+
 // State of the service.
 func (e *ServiceEntry) State() ServiceState {
 	e.stateMx.RLock()
@@ -171,7 +365,7 @@ func (e *ServiceEntry) State() ServiceState {
 }
 
 func (e *ServiceEntry) changeState(ctx context.Context, status types.ServiceStatus) error {
-	ctx = e.changeContext(ctx)
+	ctx = e.changeContext(ctx, true)
 	e.push(ctx, ServiceState{Status: status})
 	e.stateMx.RLock()
 	defer e.stateMx.RUnlock()
@@ -181,25 +375,93 @@ func (e *ServiceEntry) changeState(ctx context.Context, status types.ServiceStat
 	return nil
 }
 
-func (e *ServiceEntry) errorsLoop() {
+// eventsLoop handles runtime errors and reports of the service until it's closed.
+func (e *ServiceEntry) eventsLoop() {
 	defer e.doneWg.Done()
 
 	for {
 		select {
 		case err := <-e.errCh:
-			ctx := e.changeContext(context.Background())
-			e.push(ctx, ServiceState{Status: types.ServiceStatusError, Error: err})
+			e.handleError(err)
+		case <-e.reportsCh:
+			for _, r := range e.takeReports() {
+				if r.err != nil {
+					e.handleError(r.err)
+					continue
+				}
+				e.handleReport(r.report)
+			}
 		case <-e.closeCh:
 			return
 		}
 	}
 }
 
-func (e *ServiceEntry) changeContext(ctx context.Context) context.Context {
+// handleReport of the service, readiness and degradation reports change
+// service status, other reports are sent to state monitor as is.
+func (e *ServiceEntry) handleReport(report Report) {
+	ctx := context.Background()
+	switch report.Kind {
+	case ReportReady:
+		if e.markReady(true) {
+			e.push(ctx, reportedState(types.ServiceStatusReady, types.ServiceStatusRunning))
+		}
+	case ReportDegraded:
+		state := reportedState(types.ServiceStatusDegraded, types.ServiceStatusRunning,
+			types.ServiceStatusReady, types.ServiceStatusDegraded)
+		state.Reason = report.Reason
+		e.push(ctx, state)
+	case ReportRecovered:
+		e.push(ctx, reportedState(types.ServiceStatusRunning, types.ServiceStatusDegraded))
+	case ReportFinished:
+		e.finishRun(report.Error)
+	default:
+		e.stateCh <- ServiceState{Status: e.State().Status, Report: &report}
+	}
+}
+
+// resetReadiness of the service on startup.
+func (e *ServiceEntry) resetReadiness() {
+	e.readyMx.Lock()
+	e.ready = false
+	e.readyMx.Unlock()
+}
+
+// markReady marks service as ready if readiness was signaled or not required,
+// it returns true if service is running and should transition to ready status.
+// Readiness signal is remembered if service is still starting.
+func (e *ServiceEntry) markReady(signaled bool) bool {
+	e.readyMx.Lock()
+	defer e.readyMx.Unlock()
+
+	if signaled || !e.cfg.ReadinessSignal {
+		e.ready = true
+	}
+	return e.ready && e.State().Status == types.ServiceStatusRunning
+}
+
+func (e *ServiceEntry) handleError(err error) {
+	if e.classify(err) == types.ErrorIgnorable {
+		e.logger.Log(logging.LevelWarn, "ignoring service error", logging.Err(err))
+		e.stateCh <- ServiceState{
+			Status: e.State().Status,
+			Report: &Report{Kind: ReportIgnored, Time: time.Now(), Error: err},
+		}
+		return
+	}
+	// pending transitions of running service, e.g. readiness, are not
+	// interrupted, the error is handled after them
+	ctx := e.changeContext(context.Background(), !e.State().Status.Up())
+	e.push(ctx, ServiceState{Status: types.ServiceStatusError, Error: err})
+}
+
+// changeContext returns new cancellable context of service operation,
+// the context of previous operation is cancelled if interrupt is true.
+func (e *ServiceEntry) changeContext(ctx context.Context, interrupt bool) context.Context {
 	e.cancelMx.Lock()
 	defer e.cancelMx.Unlock()
 
-	if cancelFn := e.cancelFn; cancelFn != nil {
+	if cancelFn := e.cancelFn; interrupt && cancelFn != nil {
 		cancelFn()
 	}
 	ctx, e.cancelFn = context.WithCancel(ctx)
@@ -215,7 +477,7 @@ func (e *ServiceEntry) push(ctx context.Context, state ServiceState) {
 	atomic.StoreInt32(&e.running, serviceLoopStopped)
 	e.stateMx.RLock()
 	if e.state.Status == types.ServiceStatusError && isCtxErr(e.state.Error) {
-		fmt.Println("DEBUG: stop on context err")
+		e.logger.Log(logging.LevelDebug, "service loop stopped on context error", logging.Err(e.state.Error))
 		e.stateMx.RUnlock()
 		return
 	}
@@ -235,9 +497,15 @@ func (e *ServiceEntry) loop(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
+			state := ServiceState{Status: types.ServiceStatusError, Error: ctx.Err()}
+			if item, ok := e.sq.pop(); ok && item.Status == types.ServiceStatusError && item.Error != nil {
+				// transition failed on context timeout, keep its error as more specific
+				state.Error = item.Error
+			}
 			e.stateMx.Lock()
-			e.state = ServiceState{Status: types.ServiceStatusError, Error: ctx.Err()}
+			e.state = state
 			e.stateMx.Unlock()
+			e.notify(state)
 			return
 		default:
 		}
@@ -247,26 +515,76 @@ func (e *ServiceEntry) loop(ctx context.Context) {
 			return
 		}
 		e.applyTransition(ctx, item)
-		e.stateCh <- item
 	}
 }
 
+// notify state monitor about service state change,
+// it's called from service loop only.
+func (e *ServiceEntry) notify(state ServiceState) {
+	if e.restartState != nil {
+		state.Attempt = e.restartState.tryCount
+	}
+	e.stateCh <- state
+}
+
 func (e *ServiceEntry) applyTransition(ctx context.Context, state ServiceState) {
+	if len(state.onlyFrom) > 0 && !hasStatus(state.onlyFrom, e.state.Status) {
+		// service status was changed after the state was reported
+		return
+	}
 	transition := stateTransition{e.state.Status, state.Status}
+	handler, ok := e.transitionsSpec[transition]
+	state.Final = state.Status == types.ServiceStatusError && !ok
 	e.stateMx.Lock()
 	e.state = state
 	e.stateMx.Unlock()
-	if handler, ok := e.transitionsSpec[transition]; ok {
+	e.notify(state)
+	if ok {
 		err := handler(ctx, e, transition)
 		if err != nil {
+			e.logger.Log(logging.LevelDebug, "service transition failed",
+				logging.Any("from", transition[0]), logging.Any("to", transition[1]), logging.Err(err))
 			e.sq.push(ServiceState{Status: types.ServiceStatusError, Error: err})
 			return
 		}
 	}
 }
 
-// Close service entry.
+// classify runtime error of the service.
+func (e *ServiceEntry) classify(err error) types.ErrorClass {
+	if class, ok := types.ClassOf(err); ok {
+		return class
+	}
+	if e.cfg.ErrorClassifier != nil {
+		return e.cfg.ErrorClassifier(err)
+	}
+	return types.ErrorTransient
+}
+
+// startSpan of service operation with service name and attempt attributes.
+func (e *ServiceEntry) startSpan(ctx context.Context, name string,
+	attrs ...tracing.Attribute,
+) (context.Context, tracing.Span) {
+	var attempt int
+	if e.restartState != nil {
+		attempt = e.restartState.tryCount
+	}
+	attrs = append([]tracing.Attribute{
+		tracing.Attr("service", e.cfg.Name),
+		tracing.Attr("attempt", attempt),
+	}, attrs...)
+	return e.tracer.Start(ctx, name, attrs...)
+}
+
+// Close service entry, it can be called multiple times.
 func (e *ServiceEntry) Close() {
+	e.reportsMx.Lock()
+	if e.closed {
+		e.reportsMx.Unlock()
+		return
+	}
+	e.closed = true
+	e.reportsMx.Unlock()
 	close(e.closeCh)
 	e.doneWg.Wait()
 }
@@ -3,6 +3,8 @@ package lifecycle
 import (
 	"context"
 	"errors"
+	"runtime/pprof"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -20,7 +22,7 @@ func TestStartFlow(t *testing.T) {
 		svc := newTestServiceEntry(t, cfg)
 		err := svc.Start(ctx)
 		require.NoError(t, err)
-		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
 		require.NoError(t, svc.State().Error)
 	})
 	t.Run("startup error", func(t *testing.T) {
@@ -37,6 +39,21 @@ func TestStartFlow(t *testing.T) {
 		require.Equal(t, types.ServiceStatusError, svc.State().Status)
 		require.ErrorIs(t, svc.State().Error, targetErr)
 	})
+	t.Run("startup panic", func(t *testing.T) {
+		ctx := newTestContext(t)
+		cfg := types.ServiceConfig{
+			StartupHook: func(context.Context, chan<- error) error {
+				panic("test panic")
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		err := svc.Start(ctx)
+		var panicErr *types.PanicError
+		require.ErrorAs(t, err, &panicErr)
+		require.Equal(t, "test panic", panicErr.Value)
+		require.Contains(t, string(panicErr.Stack), "service_test.go")
+		require.Equal(t, types.ServiceStatusError, svc.State().Status)
+	})
 	t.Run("startup error timeout", func(t *testing.T) {
 		ctx := newTestContext(t)
 		ctx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
@@ -111,7 +128,7 @@ func TestRunningFlow(t *testing.T) {
 			t.Fatalf("context canceled: %v", ctx.Err())
 		case <-time.After(delay * 10):
 		}
-		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
 		require.NoError(t, svc.State().Error)
 	})
 	t.Run("runtime error recover many", func(t *testing.T) {
@@ -134,7 +151,7 @@ func TestRunningFlow(t *testing.T) {
 			t.Fatalf("context canceled: %v", ctx.Err())
 		case <-time.After(delay * 10):
 		}
-		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
 		require.NoError(t, svc.State().Error)
 	})
 	t.Run("runtime error recover many fail", func(t *testing.T) {
@@ -160,6 +177,389 @@ func TestRunningFlow(t *testing.T) {
 		require.Equal(t, types.ServiceStatusError, svc.State().Status)
 		require.ErrorIs(t, svc.State().Error, targetErr)
 	})
+	t.Run("runtime panic recover", func(t *testing.T) {
+		ctx := newTestContext(t)
+		var panicked int32
+		cfg := types.ServiceConfig{
+			StartupHook: func(_ context.Context, errCh chan<- error) error {
+				types.Go(errCh, func() error {
+					if atomic.CompareAndSwapInt32(&panicked, 0, 1) {
+						panic("test runtime panic")
+					}
+					return nil
+				})
+				return nil
+			},
+			RestartPolicy: types.ServiceRestartPolicy{
+				RestartOnFailure: true,
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		err := svc.Start(ctx)
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			return atomic.LoadInt32(&panicked) == 1 && svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("runtime permanent error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test permanent error")
+		delay := time.Millisecond * 2
+		sh := newStartupHookWithRuntimeErrOnce(types.Permanent(targetErr), delay)
+		cfg := types.ServiceConfig{
+			StartupHook:   sh,
+			RestartPolicy: types.DefaultRestartPolicy,
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusError
+		}, time.Second, time.Millisecond)
+		time.Sleep(delay * 5)
+		require.Equal(t, types.ServiceStatusError, svc.State().Status)
+		require.ErrorIs(t, svc.State().Error, targetErr)
+	})
+	t.Run("runtime error classifier", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test ignorable error")
+		delay := time.Millisecond * 2
+		sh := newStartupHookWithRuntimeErrOnce(targetErr, delay)
+		cfg := types.ServiceConfig{
+			StartupHook: sh,
+			ErrorClassifier: func(err error) types.ErrorClass {
+				if errors.Is(err, targetErr) {
+					return types.ErrorIgnorable
+				}
+				return types.ErrorTransient
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		time.Sleep(delay * 5)
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
+		require.NoError(t, svc.State().Error)
+	})
+	t.Run("reporter fail", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test reported error")
+		reported := make(chan types.Reporter, 1)
+		cfg := types.ServiceConfig{
+			StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+				reported <- r
+				return nil
+			}),
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		r := <-reported
+		r.Fail(targetErr)
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusError
+		}, time.Second, time.Millisecond)
+		require.ErrorIs(t, svc.State().Error, targetErr)
+
+		svc.Close()
+		// reporter must not block after service is closed
+		r.Fail(targetErr)
+		r.Heartbeat()
+	})
+	t.Run("readiness signal", func(t *testing.T) {
+		ctx := newTestContext(t)
+		reported := make(chan types.Reporter, 1)
+		cfg := types.ServiceConfig{
+			ReadinessSignal: true,
+			StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+				reported <- r
+				return nil
+			}),
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		(<-reported).Ready()
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("readiness signal in hook", func(t *testing.T) {
+		ctx := newTestContext(t)
+		cfg := types.ServiceConfig{
+			ReadinessSignal: true,
+			StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+				r.Ready()
+				time.Sleep(time.Millisecond * 5)
+				return nil
+			}),
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("pause and resume", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test pause error")
+		var pauseErr error
+		cfg := types.ServiceConfig{
+			StartupHook: newEmptyStartupHook(),
+			PauseHook:   func(context.Context) error { return pauseErr },
+			ResumeHook:  func(context.Context) error { return nil },
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.Error(t, svc.Resume(ctx))
+		require.NoError(t, svc.Start(ctx))
+		require.NoError(t, svc.Pause(ctx))
+		require.Equal(t, types.ServiceStatusPaused, svc.State().Status)
+		require.NoError(t, svc.Resume(ctx))
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
+
+		pauseErr = targetErr
+		require.ErrorIs(t, svc.Pause(ctx), targetErr)
+		require.Equal(t, types.ServiceStatusError, svc.State().Status)
+	})
+	t.Run("degraded and recovered", func(t *testing.T) {
+		ctx := newTestContext(t)
+		reported := make(chan types.Reporter, 1)
+		cfg := types.ServiceConfig{
+			StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+				reported <- r
+				return nil
+			}),
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		r := <-reported
+		r.Degraded("test degradation")
+		require.Eventually(t, func() bool {
+			st := svc.State()
+			return st.Status == types.ServiceStatusDegraded && st.Reason == "test degradation"
+		}, time.Second, time.Millisecond)
+		r.Recovered()
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+		require.NoError(t, svc.Stop(ctx))
+		r.Degraded("late degradation")
+		time.Sleep(time.Millisecond * 5)
+		require.Equal(t, types.ServiceStatusStopped, svc.State().Status)
+	})
+	t.Run("health check", func(t *testing.T) {
+		ctx := newTestContext(t)
+		var unhealthy int32 = 1
+		cfg := types.ServiceConfig{
+			StartupHook: newEmptyStartupHook(),
+			HealthCheck: func(context.Context) error {
+				if atomic.LoadInt32(&unhealthy) == 1 {
+					return errors.New("no connection")
+				}
+				return nil
+			},
+			HealthCheckInterval: time.Millisecond,
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			st := svc.State()
+			return st.Status == types.ServiceStatusDegraded && st.Reason == "health check failed: no connection"
+		}, time.Second, time.Millisecond)
+		atomic.StoreInt32(&unhealthy, 0)
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+		require.NoError(t, svc.Stop(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusStopped
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("reload", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("invalid config")
+		var reloadErr error = targetErr
+		cfg := types.ServiceConfig{
+			StartupHook: newEmptyStartupHook(),
+			ReloadHook:  func(context.Context) error { return reloadErr },
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.Error(t, svc.Reload(ctx), "service is not started")
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+		require.ErrorIs(t, svc.Reload(ctx), targetErr)
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusDegraded
+		}, time.Second, time.Millisecond)
+		reloadErr = nil
+		require.NoError(t, svc.Reload(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusReady
+		}, time.Second, time.Millisecond)
+		require.NoError(t, svc.Stop(ctx))
+	})
+	t.Run("job completed", func(t *testing.T) {
+		ctx := newTestContext(t)
+		cfg := types.ServiceConfig{
+			Job: func(context.Context) error {
+				time.Sleep(time.Millisecond * 5)
+				return nil
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusCompleted
+		}, time.Second, time.Millisecond)
+		require.NoError(t, svc.Stop(ctx))
+		require.Equal(t, types.ServiceStatusStopped, svc.State().Status)
+	})
+	t.Run("job failed", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test job error")
+		var runs int32
+		cfg := types.ServiceConfig{
+			Job: func(context.Context) error {
+				if atomic.AddInt32(&runs, 1) == 1 {
+					return targetErr
+				}
+				return nil
+			},
+			RestartPolicy: types.ServiceRestartPolicy{RestartOnFailure: true},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusCompleted
+		}, time.Second, time.Millisecond)
+		require.Equal(t, int32(2), atomic.LoadInt32(&runs))
+	})
+	t.Run("job cancelled on stop", func(t *testing.T) {
+		ctx := newTestContext(t)
+		cfg := types.ServiceConfig{
+			Job: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.NoError(t, svc.Stop(ctx))
+		time.Sleep(time.Millisecond * 5)
+		require.Equal(t, types.ServiceStatusStopped, svc.State().Status)
+	})
+	t.Run("run service", func(t *testing.T) {
+		ctx := newTestContext(t)
+		var stopped int32
+		cfg := types.RunService("worker", func(ctx context.Context) error {
+			<-ctx.Done()
+			time.Sleep(time.Millisecond * 5)
+			atomic.StoreInt32(&stopped, 1)
+			return nil
+		})
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
+		require.NoError(t, svc.Stop(ctx))
+		require.Equal(t, int32(1), atomic.LoadInt32(&stopped), "stop should wait for run function")
+		require.Equal(t, types.ServiceStatusStopped, svc.State().Status)
+	})
+	t.Run("run service exit", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test run error")
+		var runs int32
+		cfg := types.RunService("worker", func(ctx context.Context) error {
+			if atomic.AddInt32(&runs, 1) == 1 {
+				return targetErr
+			}
+			return nil
+		})
+		cfg.RestartPolicy.RestartDelay = 0
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusStopped
+		}, time.Second, time.Millisecond)
+		require.Equal(t, int32(2), atomic.LoadInt32(&runs))
+		require.NoError(t, svc.Stop(ctx))
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusStopped
+		}, time.Second, time.Millisecond)
+	})
+	t.Run("goroutine group", func(t *testing.T) {
+		ctx := newTestContext(t)
+		var stopped int32
+		labels := make(chan string, 1)
+		cfg := types.ServiceConfig{
+			Name: "grouped",
+			StartupHook: func(ctx context.Context, _ chan<- error) error {
+				types.GroupFrom(ctx).GoNamed("flusher", func(ctx context.Context) error {
+					service, _ := pprof.Label(ctx, "service")
+					goroutine, _ := pprof.Label(ctx, "goroutine")
+					labels <- service + "/" + goroutine
+					<-ctx.Done()
+					time.Sleep(time.Millisecond * 5)
+					atomic.StoreInt32(&stopped, 1)
+					return ctx.Err()
+				})
+				return nil
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		require.Equal(t, "grouped/flusher", <-labels)
+		require.NoError(t, svc.Stop(ctx))
+		require.Equal(t, int32(1), atomic.LoadInt32(&stopped), "stop should wait for goroutines")
+		require.Equal(t, types.ServiceStatusStopped, svc.State().Status)
+	})
+	t.Run("goroutine group error", func(t *testing.T) {
+		ctx := newTestContext(t)
+		targetErr := errors.New("test goroutine error")
+		fail := make(chan struct{})
+		cfg := types.ServiceConfig{
+			StartupHook: func(ctx context.Context, _ chan<- error) error {
+				types.GroupFrom(ctx).Go(func(context.Context) error {
+					<-fail
+					return targetErr
+				})
+				return nil
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		close(fail)
+		require.Eventually(t, func() bool {
+			return svc.State().Status == types.ServiceStatusError
+		}, time.Second, time.Millisecond)
+		require.ErrorIs(t, svc.State().Error, targetErr)
+	})
+	t.Run("goroutine group leak", func(t *testing.T) {
+		ctx := newTestContext(t)
+		block := make(chan struct{})
+		t.Cleanup(func() { close(block) })
+		cfg := types.ServiceConfig{
+			StartupHook: func(ctx context.Context, _ chan<- error) error {
+				g := types.GroupFrom(ctx)
+				g.Go(func(context.Context) error {
+					<-block
+					return nil
+				})
+				g.GoNamed("blocker", func(context.Context) error {
+					<-block
+					return nil
+				})
+				return nil
+			},
+		}
+		svc := newTestServiceEntry(t, cfg)
+		require.NoError(t, svc.Start(ctx))
+		stopCtx, cancel := context.WithTimeout(ctx, time.Millisecond*10)
+		defer cancel()
+		err := svc.Stop(stopCtx)
+		require.ErrorIs(t, err, types.ErrGoroutineLeak)
+		require.Contains(t, err.Error(), "TestRunningFlow")
+		require.Contains(t, err.Error(), "blocker")
+	})
 	t.Run("runtime error recover delay", func(t *testing.T) {
 		ctx := newTestContext(t)
 		targetErr := errors.New("test runtime error 5")
@@ -181,7 +581,7 @@ func TestRunningFlow(t *testing.T) {
 			t.Fatalf("context canceled: %v", ctx.Err())
 		case <-time.After(delay * 20):
 		}
-		require.Equal(t, types.ServiceStatusRunning, svc.State().Status)
+		require.Equal(t, types.ServiceStatusReady, svc.State().Status)
 		require.NoError(t, svc.State().Error)
 	})
 }
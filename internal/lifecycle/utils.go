@@ -3,8 +3,19 @@ package lifecycle
 import (
 	"context"
 	"errors"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
 )
 
 func isCtxErr(err error) bool {
 	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
 }
+
+func hasStatus(statuses []types.ServiceStatus, status types.ServiceStatus) bool {
+	for _, s := range statuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
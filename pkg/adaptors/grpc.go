@@ -5,6 +5,7 @@ import (
 	"net"
 	"time"
 
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 	"github.com/g4s8/go-lifecycle/pkg/types"
 	"github.com/pkg/errors"
 	"google.golang.org/grpc"
@@ -15,12 +16,31 @@ type GRPCService struct {
 	addr string
 	srv  *grpc.Server
 
-	l net.Listener
+	l          net.Listener
+	onShutdown []func()
+	logger     logging.Logger
 }
 
 // NewGRPCService creates new gRPC server adapter.
 func NewGRPCService(addr string, srv *grpc.Server) *GRPCService {
-	return &GRPCService{addr: addr, srv: srv}
+	return &GRPCService{addr: addr, srv: srv, logger: logging.Nop}
+}
+
+// SetLogger sets logger of the service, it should be called before startup.
+func (s *GRPCService) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// Server returns underlying gRPC server, it could be used
+// to register additional gRPC services before startup.
+func (s *GRPCService) Server() *grpc.Server {
+	return s.srv
+}
+
+// OnShutdown registers a function to call on shutdown before
+// gracefully stopping gRPC server, e.g. to close long-running streams.
+func (s *GRPCService) OnShutdown(fn func()) {
+	s.onShutdown = append(s.onShutdown, fn)
 }
 
 // RegisterLifecycle registers service in lifecycle manager.
@@ -43,6 +63,7 @@ func (s *GRPCService) Start(ctx context.Context, errCh chan<- error) error {
 	if err != nil {
 		return errors.Wrap(err, "listed address")
 	}
+	s.logger.Log(logging.LevelInfo, "grpc service listening", logging.String("addr", s.l.Addr().String()))
 	go func() {
 		if err := s.srv.Serve(s.l); err != nil {
 			if errors.Is(err, grpc.ErrServerStopped) {
@@ -55,6 +76,9 @@ func (s *GRPCService) Start(ctx context.Context, errCh chan<- error) error {
 }
 
 func (s *GRPCService) Stop(ctx context.Context) error {
+	for _, fn := range s.onShutdown {
+		fn()
+	}
 	stopCh := make(chan struct{}, 1)
 	go func() {
 		s.srv.GracefulStop()
@@ -62,6 +86,7 @@ func (s *GRPCService) Stop(ctx context.Context) error {
 	}()
 	select {
 	case <-ctx.Done():
+		s.logger.Log(logging.LevelWarn, "grpc graceful stop timed out, stopping forcibly", logging.Err(ctx.Err()))
 		s.srv.Stop()
 		return ctx.Err()
 	case <-stopCh:
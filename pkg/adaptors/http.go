@@ -7,18 +7,25 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 	"github.com/g4s8/go-lifecycle/pkg/types"
 	"github.com/pkg/errors"
 )
 
 // HTTPService is an adapter for http.Server to implement lifecycle hooks.
 type HTTPService struct {
-	srv *http.Server
+	srv    *http.Server
+	logger logging.Logger
 }
 
 // NewHTTPService creates new HTTPService adaptor.
 func NewHTTPService(srv *http.Server) *HTTPService {
-	return &HTTPService{srv: srv}
+	return &HTTPService{srv: srv, logger: logging.Nop}
+}
+
+// SetLogger sets logger of the service, it should be called before startup.
+func (s *HTTPService) SetLogger(logger logging.Logger) {
+	s.logger = logger
 }
 
 // RegisterLifecycle registers this service in lifecycle manager.
@@ -44,6 +51,7 @@ func (s *HTTPService) Start(ctx context.Context, errCh chan<- error) error {
 	if err != nil {
 		return fmt.Errorf("listen tcp: %w", err)
 	}
+	s.logger.Log(logging.LevelInfo, "http service listening", logging.String("addr", ln.Addr().String()))
 	go func() {
 		if err := s.srv.Serve(ln); err != nil {
 			if err != http.ErrServerClosed {
@@ -56,6 +64,7 @@ func (s *HTTPService) Start(ctx context.Context, errCh chan<- error) error {
 }
 
 func (s *HTTPService) Stop(ctx context.Context) error {
+	s.logger.Log(logging.LevelInfo, "http service shutting down")
 	if err := s.srv.Shutdown(ctx); err != nil {
 		return errors.Wrap(err, "shutdown")
 	}
@@ -0,0 +1,112 @@
+package health
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+)
+
+var _ http.Handler = (*Admin)(nil)
+
+// AdminHeader is a request header with admin token required by admin API.
+// Browsers don't send custom headers in cross-origin requests without
+// CORS preflight, it protects admin API from cross-site requests too.
+const AdminHeader = "X-Lifecycle-Admin"
+
+// AdminConfig is a configuration of admin API.
+type AdminConfig struct {
+	// Token is a secret value of AdminHeader required by admin API,
+	// all requests are rejected if it's empty.
+	Token string
+	// Protected is a list of services which can't be stopped or restarted
+	// with admin API, health service itself is always protected.
+	Protected []string
+}
+
+// ServiceController controls lifecycle services by name.
+type ServiceController interface {
+	StopService(name string) error
+	RestartService(name string) error
+}
+
+// Admin is an HTTP API to control lifecycle services.
+// It accepts POST requests with `service` and `action` parameters,
+// where action is either "stop" or "restart", and requires AdminHeader
+// with configured token. It could be mounted to health service:
+//
+//	hs.Mount("/admin", health.NewAdmin(lf, health.AdminConfig{Token: os.Getenv("ADMIN_TOKEN")}))
+type Admin struct {
+	ctrl      ServiceController
+	token     string
+	protected map[string]struct{}
+	logger    logging.Logger
+}
+
+// NewAdmin creates new admin API handler.
+func NewAdmin(ctrl ServiceController, cfg AdminConfig) *Admin {
+	protected := map[string]struct{}{ServiceName: {}}
+	for _, name := range cfg.Protected {
+		protected[name] = struct{}{}
+	}
+	return &Admin{ctrl: ctrl, token: cfg.Token, protected: protected, logger: logging.Nop}
+}
+
+// SetLogger sets logger for admin actions.
+func (a *Admin) SetLogger(logger logging.Logger) {
+	a.logger = logger
+}
+
+func (a *Admin) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if a.token == "" {
+		http.Error(w, "admin API is disabled", http.StatusForbidden)
+		return
+	}
+	token := req.Header.Get(AdminHeader)
+	if subtle.ConstantTimeCompare([]byte(token), []byte(a.token)) != 1 {
+		http.Error(w, "invalid "+AdminHeader+" header", http.StatusUnauthorized)
+		return
+	}
+	name := req.FormValue("service")
+	if _, ok := a.protected[name]; ok {
+		http.Error(w, "service "+name+" is protected", http.StatusForbidden)
+		return
+	}
+	var err error
+	action := req.FormValue("action")
+	switch action {
+	case "stop":
+		err = a.ctrl.StopService(name)
+	case "restart":
+		err = a.ctrl.RestartService(name)
+	default:
+		http.Error(w, "unknown action: "+action, http.StatusBadRequest)
+		return
+	}
+	fields := []logging.Field{
+		logging.String("service", name),
+		logging.String("action", action),
+		logging.String("remote", req.RemoteAddr),
+	}
+	if err != nil {
+		a.logger.Log(logging.LevelWarn, "admin action failed", append(fields, logging.Err(err))...)
+	} else {
+		a.logger.Log(logging.LevelInfo, "admin action", fields...)
+	}
+	if errors.Is(err, lifecycle.ErrServiceNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
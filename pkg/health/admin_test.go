@@ -0,0 +1,81 @@
+package health
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAdmin(t *testing.T) {
+	lf := lifecycle.New(lifecycle.DefaultConfig)
+	t.Cleanup(func() { lf.Close() })
+	var starts, stops int32
+	lf.RegisterService(types.ServiceConfig{
+		Name: "web",
+		StartupHook: func(context.Context, chan<- error) error {
+			atomic.AddInt32(&starts, 1)
+			return nil
+		},
+		ShutdownHook: func(context.Context) error {
+			atomic.AddInt32(&stops, 1)
+			return nil
+		},
+	})
+	lf.RegisterStartupHook(ServiceName, func(context.Context, chan<- error) error { return nil })
+	require.NoError(t, lf.Start())
+
+	admin := NewAdmin(lf, AdminConfig{Token: "secret"})
+	do := func(method, token, service, action string) *httptest.ResponseRecorder {
+		form := url.Values{"service": {service}, "action": {action}}
+		req := httptest.NewRequest(method, "/admin", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if token != "" {
+			req.Header.Set(AdminHeader, token)
+		}
+		rec := httptest.NewRecorder()
+		admin.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("wrong method", func(t *testing.T) {
+		rec := do(http.MethodGet, "secret", "web", "stop")
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		require.Equal(t, http.MethodPost, rec.Header().Get("Allow"))
+	})
+	t.Run("missing header", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "", "web", "stop").Code)
+	})
+	t.Run("wrong token", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodPost, "1", "web", "stop").Code)
+	})
+	t.Run("unknown action", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPost, "secret", "web", "kill").Code)
+	})
+	t.Run("unknown service", func(t *testing.T) {
+		require.Equal(t, http.StatusNotFound, do(http.MethodPost, "secret", "db", "stop").Code)
+	})
+	t.Run("protected service", func(t *testing.T) {
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "secret", ServiceName, "restart").Code)
+	})
+	t.Run("restart", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do(http.MethodPost, "secret", "web", "restart").Code)
+		require.Equal(t, int32(2), atomic.LoadInt32(&starts))
+		require.Equal(t, int32(1), atomic.LoadInt32(&stops))
+	})
+	t.Run("stop", func(t *testing.T) {
+		require.Equal(t, http.StatusNoContent, do(http.MethodPost, "secret", "web", "stop").Code)
+		require.Equal(t, int32(2), atomic.LoadInt32(&stops))
+	})
+	t.Run("disabled", func(t *testing.T) {
+		admin = NewAdmin(lf, AdminConfig{})
+		require.Equal(t, http.StatusForbidden, do(http.MethodPost, "", "web", "stop").Code)
+	})
+}
@@ -0,0 +1,305 @@
+package health
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+)
+
+var (
+	_ Handler           = (*Dashboard)(nil)
+	_ TransitionHandler = (*Dashboard)(nil)
+)
+
+const dashboardTransitions = 20
+
+// DashboardConfig is a configuration of HTML status dashboard.
+type DashboardConfig struct {
+	// EventsPath is a path of EventStream handler used for live updates,
+	// if empty, the page is reloaded periodically.
+	EventsPath string
+	// AdminPath is a path of Admin handler, if not empty, the dashboard shows
+	// restart and stop buttons for services and asks for admin token on first action.
+	AdminPath string
+}
+
+// Dashboard is a self-contained HTML page showing lifecycle services status.
+// It's driven by lifecycle state updates and service transitions,
+// and could be mounted to health service:
+//
+//	hs.Mount("/events", health.NewEventStream(0))
+//	hs.Mount("/dashboard", health.NewDashboard(health.DashboardConfig{EventsPath: "/events"}))
+type Dashboard struct {
+	cfg DashboardConfig
+
+	services    map[int]*dashboardService
+	transitions []dashboardTransition
+	mx          sync.Mutex
+}
+
+type dashboardService struct {
+	ID            int        `json:"id"`
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	Since         time.Time  `json:"since"`
+	RunningSince  *time.Time `json:"runningSince,omitempty"`
+	Restarts      int        `json:"restarts"`
+	LastError     string     `json:"lastError,omitempty"`
+	LastErrorTime *time.Time `json:"lastErrorTime,omitempty"`
+}
+
+type dashboardTransition struct {
+	Time    time.Time `json:"time"`
+	Service string    `json:"service"`
+	From    string    `json:"from"`
+	To      string    `json:"to"`
+	Error   string    `json:"error,omitempty"`
+}
+
+type dashboardData struct {
+	Services    []dashboardService    `json:"services"`
+	Transitions []dashboardTransition `json:"transitions"`
+	EventsPath  string                `json:"eventsPath"`
+	AdminPath   string                `json:"adminPath"`
+	AdminHeader string                `json:"adminHeader"`
+}
+
+// NewDashboard creates new HTML dashboard handler.
+func NewDashboard(cfg DashboardConfig) *Dashboard {
+	return &Dashboard{
+		cfg:      cfg,
+		services: make(map[int]*dashboardService),
+	}
+}
+
+// Update dashboard with new lifecycle state.
+func (d *Dashboard) Update(states []lifecycle.ServiceState) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	for _, st := range states {
+		svc := &dashboardService{
+			ID:       st.ID,
+			Name:     st.Name,
+			Status:   st.Status.String(),
+			Since:    st.Since,
+			Restarts: st.Restarts,
+		}
+		if st.Status.Up() {
+			since := st.Since
+			if prev, ok := d.services[st.ID]; ok && prev.RunningSince != nil {
+				// uptime is kept on transitions between running, ready and degraded
+				since = *prev.RunningSince
+			}
+			svc.RunningSince = &since
+		}
+		if n := len(st.Errors); n > 0 {
+			last := st.Errors[n-1]
+			svc.LastError = last.Error.Error()
+			svc.LastErrorTime = &last.Time
+		}
+		d.services[st.ID] = svc
+	}
+}
+
+// Transition adds service transition to recent transitions list.
+func (d *Dashboard) Transition(ev lifecycle.TransitionEvent) {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	item := dashboardTransition{
+		Time:    ev.At,
+		Service: ev.Service,
+		From:    ev.From.String(),
+		To:      ev.To.String(),
+	}
+	if ev.Error != nil {
+		item.Error = ev.Error.Error()
+	}
+	if len(d.transitions) == dashboardTransitions {
+		d.transitions = append(d.transitions[:0], d.transitions[1:]...)
+	}
+	d.transitions = append(d.transitions, item)
+}
+
+func (d *Dashboard) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	data := d.data()
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "no-cache")
+	if err := dashboardTemplate.Execute(w, data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (d *Dashboard) data() dashboardData {
+	d.mx.Lock()
+	defer d.mx.Unlock()
+
+	res := dashboardData{
+		Services:    make([]dashboardService, 0, len(d.services)),
+		Transitions: make([]dashboardTransition, len(d.transitions)),
+		EventsPath:  d.cfg.EventsPath,
+		AdminPath:   d.cfg.AdminPath,
+		AdminHeader: AdminHeader,
+	}
+	for _, svc := range d.services {
+		res.Services = append(res.Services, *svc)
+	}
+	sort.Slice(res.Services, func(i, j int) bool {
+		return res.Services[i].ID < res.Services[j].ID
+	})
+	copy(res.Transitions, d.transitions)
+	return res
+}
+
+var dashboardTemplate = template.Must(template.New("dashboard").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Lifecycle dashboard</title>
+{{if not .EventsPath}}<meta http-equiv="refresh" content="5">{{end}}
+<style>
+body { font-family: sans-serif; margin: 2em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin-bottom: 2em; }
+th, td { text-align: left; padding: 0.4em 0.8em; border-bottom: 1px solid #ddd; }
+th { background: #f4f4f4; }
+.status { font-weight: bold; }
+.Running, .Ready { color: #1a7f37; }
+.Error { color: #cf222e; }
+.Starting, .Stopping, .Degraded { color: #9a6700; }
+.Init, .Stopped, .Paused { color: #57606a; }
+.Completed { color: #0969da; }
+.error { color: #cf222e; font-family: monospace; }
+</style>
+</head>
+<body>
+<h1>Lifecycle dashboard</h1>
+<h2>Services</h2>
+<table>
+<thead><tr><th>ID</th><th>Name</th><th>Status</th><th>Uptime</th><th>Restarts</th><th>Last error</th><th></th></tr></thead>
+<tbody id="services"></tbody>
+</table>
+<h2>Recent transitions</h2>
+<table>
+<thead><tr><th>Time</th><th>Service</th><th>From</th><th>To</th><th>Error</th></tr></thead>
+<tbody id="transitions"></tbody>
+</table>
+<script>
+"use strict";
+const state = {{.}};
+const maxTransitions = 20;
+const upStatuses = ["Running", "Ready", "Degraded"];
+
+function el(tag, text, cls) {
+	const e = document.createElement(tag);
+	if (text !== undefined) e.textContent = text;
+	if (cls) e.className = cls;
+	return e;
+}
+
+function duration(since) {
+	let s = Math.max(0, Math.floor((Date.now() - new Date(since).getTime()) / 1000));
+	const d = Math.floor(s / 86400); s %= 86400;
+	const h = Math.floor(s / 3600); s %= 3600;
+	const m = Math.floor(s / 60); s %= 60;
+	return (d ? d + "d " : "") + (d || h ? h + "h " : "") + (d || h || m ? m + "m " : "") + s + "s";
+}
+
+function action(svc, name) {
+	let token = sessionStorage.getItem("adminToken");
+	if (!token) {
+		token = prompt("Admin token");
+		if (!token) return;
+	}
+	const body = new URLSearchParams({service: svc.name, action: name});
+	const headers = {};
+	headers[state.adminHeader] = token;
+	fetch(state.adminPath, {method: "POST", headers: headers, body: body}).then(function (rsp) {
+		if (rsp.ok) {
+			sessionStorage.setItem("adminToken", token);
+			return;
+		}
+		if (rsp.status === 401) sessionStorage.removeItem("adminToken");
+		rsp.text().then(function (t) { alert(name + " " + svc.name + ": " + t); });
+	});
+}
+
+function render() {
+	const services = document.getElementById("services");
+	services.replaceChildren();
+	for (const svc of state.services) {
+		const tr = el("tr");
+		tr.append(el("td", svc.id), el("td", svc.name), el("td", svc.status, "status " + svc.status),
+			el("td", svc.runningSince ? duration(svc.runningSince) : "-"), el("td", svc.restarts),
+			el("td", svc.lastError ? svc.lastError + " (" + new Date(svc.lastErrorTime).toLocaleString() + ")" : "", "error"));
+		const actions = el("td");
+		if (state.adminPath) {
+			for (const name of ["restart", "stop"]) {
+				const btn = el("button", name);
+				btn.onclick = function () { action(svc, name); };
+				actions.append(btn, " ");
+			}
+		}
+		tr.append(actions);
+		services.append(tr);
+	}
+	const transitions = document.getElementById("transitions");
+	transitions.replaceChildren();
+	for (const t of state.transitions.slice().reverse()) {
+		const tr = el("tr");
+		tr.append(el("td", new Date(t.time).toLocaleString()), el("td", t.service),
+			el("td", t.from), el("td", t.to, t.to), el("td", t.error || "", "error"));
+		transitions.append(tr);
+	}
+}
+
+function apply(ev) {
+	update(ev.service);
+	state.transitions.push({time: ev.time, service: ev.service.name, from: ev.from,
+		to: ev.service.status, error: ev.service.error});
+	if (state.transitions.length > maxTransitions) state.transitions.shift();
+}
+
+function update(s) {
+	let svc = state.services.find(function (x) { return x.id === s.id; });
+	if (!svc) {
+		svc = {id: s.id, name: s.name};
+		state.services.push(svc);
+		state.services.sort(function (a, b) { return a.id - b.id; });
+	}
+	svc.status = s.status;
+	svc.since = s.since;
+	svc.restarts = s.restarts;
+	const up = upStatuses.includes(s.status);
+	svc.runningSince = up ? (svc.runningSince || s.since) : null;
+	if (s.errors && s.errors.length > 0) {
+		const last = s.errors[s.errors.length - 1];
+		svc.lastError = last.error;
+		svc.lastErrorTime = last.time;
+	}
+}
+
+render();
+setInterval(render, 1000);
+if (state.eventsPath) {
+	const source = new EventSource(state.eventsPath);
+	source.addEventListener("state", function (e) {
+		apply(JSON.parse(e.data));
+		render();
+	});
+	source.addEventListener("snapshot", function (e) {
+		for (const s of JSON.parse(e.data)) {
+			update(s);
+		}
+		render();
+	});
+}
+</script>
+</body>
+</html>
+`))
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDashboard(t *testing.T) {
+	d := NewDashboard(DashboardConfig{EventsPath: "/events", AdminPath: "/admin"})
+	from := types.ServiceStatusInit
+	transition := func(to types.ServiceStatus, err error) {
+		d.Transition(lifecycle.TransitionEvent{ID: 0, Service: "web", From: from, To: to, Error: err, At: time.Now()})
+		from = to
+	}
+	transition(types.ServiceStatusRunning, nil)
+	transition(types.ServiceStatusError, errors.New("boom"))
+	transition(types.ServiceStatusStarting, nil)
+	d.Update([]lifecycle.ServiceState{{
+		ID: 0, Name: "web", Status: types.ServiceStatusStarting, Restarts: 1,
+		Errors: []lifecycle.ErrorRecord{{Time: time.Now(), Error: errors.New("boom")}},
+	}})
+
+	data := d.data()
+	require.Len(t, data.Services, 1)
+	require.Equal(t, "Starting", data.Services[0].Status)
+	require.Equal(t, 1, data.Services[0].Restarts)
+	require.Equal(t, "boom", data.Services[0].LastError)
+	require.Len(t, data.Transitions, 3)
+	require.Equal(t, "Running", data.Transitions[1].From)
+	require.Equal(t, "boom", data.Transitions[1].Error)
+
+	rec := httptest.NewRecorder()
+	d.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Contains(t, rec.Body.String(), `"eventsPath":"/events"`)
+	require.Contains(t, rec.Body.String(), `"lastError":"boom"`)
+
+	since := time.Now().Add(-time.Minute)
+	d.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusRunning, Since: since}})
+	d.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusReady, Since: time.Now()}})
+	data = d.data()
+	require.NotNil(t, data.Services[0].RunningSince, "ready service is up")
+	require.Equal(t, since, *data.Services[0].RunningSince)
+}
@@ -0,0 +1,254 @@
+package health
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+)
+
+var (
+	_ Handler           = (*EventStream)(nil)
+	_ TransitionHandler = (*EventStream)(nil)
+)
+
+const (
+	// DefaultEventsHistorySize is a default number of events
+	// kept in memory to resume event streams.
+	DefaultEventsHistorySize = 100
+
+	eventsClientBuffer     = 64
+	eventsKeepAliveTimeout = 30 * time.Second
+)
+
+// EventStream streams lifecycle service transitions as Server-Sent Events.
+//
+// Each transition is sent as "state" event with JSON data and sequential ID.
+// New clients receive "snapshot" event with the current state of all services first.
+// Clients could resume the stream with Last-Event-ID header, missed events are
+// replayed from bounded in-memory history, if they are not available anymore,
+// the snapshot is sent instead.
+//
+// It's driven by lifecycle state updates and service transitions,
+// and could be mounted to health service:
+//
+//	hs.Mount("/events", health.NewEventStream(0))
+type EventStream struct {
+	historySize int
+	logger      logging.Logger
+
+	history []stateEvent
+	lastID  uint64
+	states  []serviceState
+	clients map[*eventsClient]struct{}
+	mx      sync.Mutex
+}
+
+type stateEvent struct {
+	ID      uint64       `json:"id"`
+	Time    time.Time    `json:"time"`
+	From    string       `json:"from"`
+	Service serviceState `json:"service"`
+}
+
+type eventsClient struct {
+	events chan stateEvent
+}
+
+// NewEventStream creates new event stream handler with specified
+// history size, if it's not positive, DefaultEventsHistorySize is used.
+func NewEventStream(historySize int) *EventStream {
+	if historySize <= 0 {
+		historySize = DefaultEventsHistorySize
+	}
+	return &EventStream{
+		historySize: historySize,
+		logger:      logging.Nop,
+		clients:     make(map[*eventsClient]struct{}),
+	}
+}
+
+// SetLogger sets logger of event stream.
+func (s *EventStream) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// Update keeps the current state of services for snapshots of new clients.
+func (s *EventStream) Update(states []lifecycle.ServiceState) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.states = make([]serviceState, len(states))
+	for i, st := range states {
+		s.states[i] = newServiceState(st)
+	}
+}
+
+// Transition sends service transition to all connected clients.
+// Slow clients which can't keep up with updates are disconnected,
+// they could resume the stream later using event history.
+func (s *EventStream) Transition(ev lifecycle.TransitionEvent) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.lastID++
+	item := stateEvent{
+		ID:      s.lastID,
+		Time:    ev.At,
+		From:    ev.From.String(),
+		Service: s.transitionState(ev),
+	}
+	if len(s.history) == s.historySize {
+		s.history = append(s.history[:0], s.history[1:]...)
+	}
+	s.history = append(s.history, item)
+	for c := range s.clients {
+		select {
+		case c.events <- item:
+		default:
+			s.logger.Log(logging.LevelWarn, "event stream client is too slow, disconnecting")
+			delete(s.clients, c)
+			close(c.events)
+		}
+	}
+}
+
+// transitionState returns state of the service after transition, details
+// are taken from the current state only if it has the same status.
+func (s *EventStream) transitionState(ev lifecycle.TransitionEvent) serviceState {
+	res := serviceState{ID: ev.ID, Name: ev.Service}
+	for _, st := range s.states {
+		if st.ID != ev.ID {
+			continue
+		}
+		if st.Status == ev.To.String() {
+			res = st
+		} else {
+			res.Criticality = st.Criticality
+		}
+		break
+	}
+	res.Status = ev.To.String()
+	res.Since = ev.At
+	res.Restarts = ev.Attempt
+	res.Error = ""
+	if ev.Error != nil {
+		res.Error = ev.Error.Error()
+	}
+	return res
+}
+
+func (s *EventStream) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	var lastID uint64
+	var resume bool
+	if h := req.Header.Get("Last-Event-ID"); h != "" {
+		id, err := strconv.ParseUint(h, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid Last-Event-ID header", http.StatusBadRequest)
+			return
+		}
+		lastID, resume = id, true
+	}
+
+	client := &eventsClient{events: make(chan stateEvent, eventsClientBuffer)}
+	snapshot, replay := s.subscribe(client, lastID, resume)
+	defer s.unsubscribe(client)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	if snapshot != nil {
+		if err := writeEvent(w, "snapshot", "", snapshot); err != nil {
+			return
+		}
+	}
+	for _, ev := range replay {
+		if err := writeEvent(w, "state", strconv.FormatUint(ev.ID, 10), ev); err != nil {
+			return
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventsKeepAliveTimeout)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case ev, ok := <-client.events:
+			if !ok {
+				return
+			}
+			if err := writeEvent(w, "state", strconv.FormatUint(ev.ID, 10), ev); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case <-req.Context().Done():
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// subscribe registers new client and returns either current snapshot or
+// events to replay after last event ID.
+func (s *EventStream) subscribe(c *eventsClient, lastID uint64,
+	resume bool,
+) (snapshot []serviceState, replay []stateEvent) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	s.clients[c] = struct{}{}
+	var firstID uint64
+	if len(s.history) > 0 {
+		firstID = s.history[0].ID
+	}
+	if !resume || lastID > s.lastID || (firstID > 0 && lastID+1 < firstID) {
+		snapshot = make([]serviceState, len(s.states))
+		copy(snapshot, s.states)
+		return
+	}
+	for _, ev := range s.history {
+		if ev.ID > lastID {
+			replay = append(replay, ev)
+		}
+	}
+	return
+}
+
+func (s *EventStream) unsubscribe(c *eventsClient) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+
+	if _, ok := s.clients[c]; ok {
+		delete(s.clients, c)
+		close(c.events)
+	}
+}
+
+func writeEvent(w io.Writer, name, id string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return err
+		}
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, payload)
+	return err
+}
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventStream(t *testing.T) {
+	es := NewEventStream(2)
+	from := types.ServiceStatusInit
+	transition := func(to types.ServiceStatus) {
+		es.Transition(lifecycle.TransitionEvent{ID: 0, Service: "web", From: from, To: to, At: time.Now()})
+		from = to
+	}
+	es.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusInit}})
+	transition(types.ServiceStatusStarting)
+	transition(types.ServiceStatusRunning)
+	// latest snapshot is ahead of delivered transitions
+	es.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusStopping}})
+	transition(types.ServiceStatusStopping)
+
+	srv := httptest.NewServer(es)
+	t.Cleanup(srv.Close)
+
+	t.Run("snapshot", func(t *testing.T) {
+		lines := readEvents(t, srv.URL, "", 1)
+		require.Equal(t, "event: snapshot", lines[0])
+		require.Contains(t, lines[1], `"status":"Stopping"`)
+	})
+	t.Run("resume", func(t *testing.T) {
+		lines := readEvents(t, srv.URL, "2", 1)
+		require.Equal(t, "id: 3", lines[0])
+		require.Equal(t, "event: state", lines[1])
+		require.Contains(t, lines[2], `"from":"Running"`)
+		require.Contains(t, lines[2], `"status":"Stopping"`)
+	})
+	t.Run("resume after history", func(t *testing.T) {
+		lines := readEvents(t, srv.URL, "0", 1)
+		require.Equal(t, "event: snapshot", lines[0])
+	})
+	t.Run("live", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodGet, srv.URL, nil)
+		require.NoError(t, err)
+		req.Header.Set("Last-Event-ID", "3")
+		rsp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		defer rsp.Body.Close()
+		require.Equal(t, "text/event-stream", rsp.Header.Get("Content-Type"))
+		transition(types.ServiceStatusStopped)
+		lines := scanEvent(t, bufio.NewScanner(rsp.Body))
+		require.Equal(t, "id: 4", lines[0])
+		require.Contains(t, lines[2], `"status":"Stopped"`)
+	})
+}
+
+func readEvents(t *testing.T, url, lastID string, count int) []string {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+	if lastID != "" {
+		req.Header.Set("Last-Event-ID", lastID)
+	}
+	rsp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer rsp.Body.Close()
+	require.Equal(t, http.StatusOK, rsp.StatusCode)
+	scanner := bufio.NewScanner(rsp.Body)
+	var lines []string
+	for i := 0; i < count; i++ {
+		lines = append(lines, scanEvent(t, scanner)...)
+	}
+	return lines
+}
+
+func scanEvent(t *testing.T, scanner *bufio.Scanner) []string {
+	t.Helper()
+	var lines []string
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			return lines
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	require.NoError(t, scanner.Err())
+	return lines
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"sync"
+
+	"github.com/g4s8/go-lifecycle/pkg/adaptors"
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+var _ healthpb.HealthServer = (*GRPCServer)(nil)
+
+// GRPCServer implements standard gRPC health checking protocol
+// (grpc.health.v1.Health) backed by lifecycle services state.
+//
+// Empty service name in request reports overall lifecycle health:
+// it's SERVING only when all critical services are running. Any other name
+// reports the status of lifecycle service with the same name.
+type GRPCServer struct {
+	healthpb.UnimplementedHealthServer
+
+	lf StatusProvider
+
+	shutdownOnce sync.Once
+	shutdownCh   chan struct{}
+}
+
+// StatusProvider is a lifecycle which provides current states of services.
+type StatusProvider interface {
+	Lifecycle
+	Statuses() []lifecycle.ServiceState
+}
+
+// NewGRPCServer creates new gRPC health server.
+func NewGRPCServer(lf StatusProvider) *GRPCServer {
+	return &GRPCServer{
+		lf:         lf,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+// Register registers health server on gRPC server.
+func (s *GRPCServer) Register(srv grpc.ServiceRegistrar) {
+	healthpb.RegisterHealthServer(srv, s)
+}
+
+// RegisterGRPCService registers health server on gRPC server of the service
+// and shuts down all watch streams when the service is stopped.
+func (s *GRPCServer) RegisterGRPCService(svc *adaptors.GRPCService) {
+	s.Register(svc.Server())
+	svc.OnShutdown(s.Shutdown)
+}
+
+// Shutdown sends NOT_SERVING status to all watchers and closes watch streams.
+// All next check requests will report NOT_SERVING status too.
+func (s *GRPCServer) Shutdown() {
+	s.shutdownOnce.Do(func() {
+		close(s.shutdownCh)
+	})
+}
+
+// Check returns current serving status of the service.
+func (s *GRPCServer) Check(ctx context.Context,
+	req *healthpb.HealthCheckRequest,
+) (*healthpb.HealthCheckResponse, error) {
+	select {
+	case <-s.shutdownCh:
+		return &healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING}, nil
+	default:
+	}
+
+	st, ok := servingStatus(req.GetService(), s.lf.Statuses())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch streams serving status of the service on each change.
+func (s *GRPCServer) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	statesCh := make(chan []lifecycle.ServiceState, 1)
+	sub := s.lf.SubscribeMonitor(statesCh)
+	defer sub.Cancel()
+
+	lastSent := healthpb.HealthCheckResponse_UNKNOWN
+	send := func(states []lifecycle.ServiceState) error {
+		st, ok := servingStatus(req.GetService(), states)
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st == lastSent {
+			return nil
+		}
+		lastSent = st
+		return stream.Send(&healthpb.HealthCheckResponse{Status: st})
+	}
+
+	if err := send(s.lf.Statuses()); err != nil {
+		return err
+	}
+	for {
+		select {
+		case states := <-statesCh:
+			if err := send(states); err != nil {
+				return err
+			}
+		case <-s.shutdownCh:
+			if lastSent == healthpb.HealthCheckResponse_NOT_SERVING {
+				return nil
+			}
+			return stream.Send(&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_NOT_SERVING})
+		case <-stream.Context().Done():
+			return status.Error(codes.Canceled, "stream has ended")
+		}
+	}
+}
+
+// servingStatus maps lifecycle state to gRPC serving status of the service.
+// It returns false if service with specified name is not registered.
+func servingStatus(name string,
+	states []lifecycle.ServiceState,
+) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if name == "" {
+		if len(states) == 0 {
+			return healthpb.HealthCheckResponse_NOT_SERVING, true
+		}
+		for _, st := range states {
+			if st.Criticality == types.ServiceOptional || st.Status == types.ServiceStatusCompleted {
+				continue
+			}
+			if !serving(st.Status) {
+				return healthpb.HealthCheckResponse_NOT_SERVING, true
+			}
+		}
+		return healthpb.HealthCheckResponse_SERVING, true
+	}
+	for _, st := range states {
+		if st.Name != name {
+			continue
+		}
+		if serving(st.Status) {
+			return healthpb.HealthCheckResponse_SERVING, true
+		}
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	return healthpb.HealthCheckResponse_UNKNOWN, false
+}
+
+// serving reports whether service with status is serving,
+// degraded service is still serving.
+func serving(status types.ServiceStatus) bool {
+	return status == types.ServiceStatusReady || status == types.ServiceStatusDegraded
+}
@@ -0,0 +1,130 @@
+package health
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestServingStatus(t *testing.T) {
+	states := []lifecycle.ServiceState{
+		{ID: 0, Name: "web", Status: types.ServiceStatusReady},
+		{ID: 1, Name: "worker", Status: types.ServiceStatusError},
+		{ID: 2, Name: "consumer", Status: types.ServiceStatusRunning},
+	}
+	for _, tc := range []struct {
+		name    string
+		service string
+		states  []lifecycle.ServiceState
+		status  healthpb.HealthCheckResponse_ServingStatus
+		found   bool
+	}{
+		{"empty lifecycle", "", nil, healthpb.HealthCheckResponse_NOT_SERVING, true},
+		{"overall failed", "", states[:2], healthpb.HealthCheckResponse_NOT_SERVING, true},
+		{"overall not ready", "", []lifecycle.ServiceState{states[0], states[2]},
+			healthpb.HealthCheckResponse_NOT_SERVING, true},
+		{"overall job completed", "", []lifecycle.ServiceState{
+			states[0],
+			{ID: 1, Name: "migrations", Status: types.ServiceStatusCompleted},
+		}, healthpb.HealthCheckResponse_SERVING, true},
+		{"overall ok", "", states[:1], healthpb.HealthCheckResponse_SERVING, true},
+		{"overall optional failed", "", []lifecycle.ServiceState{
+			states[0],
+			{ID: 1, Name: "worker", Status: types.ServiceStatusError, Criticality: types.ServiceOptional},
+		}, healthpb.HealthCheckResponse_SERVING, true},
+		{"ready service", "web", states, healthpb.HealthCheckResponse_SERVING, true},
+		{"not ready service", "consumer", states, healthpb.HealthCheckResponse_NOT_SERVING, true},
+		{"failed service", "worker", states, healthpb.HealthCheckResponse_NOT_SERVING, true},
+		{"unknown service", "db", states, healthpb.HealthCheckResponse_UNKNOWN, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			status, found := servingStatus(tc.service, tc.states)
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.status, status)
+		})
+	}
+}
+
+func TestGRPCCheck(t *testing.T) {
+	lf := lifecycle.New(lifecycle.DefaultConfig)
+	t.Cleanup(func() { lf.Close() })
+	lf.RegisterStartupHook("web", func(context.Context, chan<- error) error { return nil })
+	srv := NewGRPCServer(lf)
+	ctx := context.Background()
+
+	rsp, err := srv.Check(ctx, &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, rsp.Status)
+
+	require.NoError(t, lf.Start())
+	require.Eventually(t, func() bool {
+		rsp, err := srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "web"})
+		return err == nil && rsp.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, time.Millisecond)
+	_, err = srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "db"})
+	require.Error(t, err)
+
+	srv.Shutdown()
+	rsp, err = srv.Check(ctx, &healthpb.HealthCheckRequest{Service: "web"})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, rsp.Status)
+}
+
+type testWatchStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	ch  chan healthpb.HealthCheckResponse_ServingStatus
+}
+
+func (s *testWatchStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *testWatchStream) Send(rsp *healthpb.HealthCheckResponse) error {
+	s.ch <- rsp.Status
+	return nil
+}
+
+func TestGRPCWatch(t *testing.T) {
+	lf := lifecycle.New(lifecycle.DefaultConfig)
+	t.Cleanup(func() { lf.Close() })
+	lf.RegisterStartupHook("web", func(context.Context, chan<- error) error { return nil })
+	srv := NewGRPCServer(lf)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := &testWatchStream{ctx: ctx, ch: make(chan healthpb.HealthCheckResponse_ServingStatus, 16)}
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Watch(&healthpb.HealthCheckRequest{Service: "web"}, stream)
+	}()
+	receive := func() healthpb.HealthCheckResponse_ServingStatus {
+		t.Helper()
+		select {
+		case st := <-stream.ch:
+			return st
+		case <-time.After(time.Second):
+			t.Fatal("watch status timeout")
+		}
+		return healthpb.HealthCheckResponse_UNKNOWN
+	}
+
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, receive())
+	require.NoError(t, lf.Start())
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, receive())
+	require.NoError(t, lf.Stop())
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, receive())
+
+	srv.Shutdown()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("watch is not finished on shutdown")
+	}
+}
@@ -4,22 +4,89 @@ import (
 	"encoding/json"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
-	"github.com/g4s8/go-lifecycle/pkg/types"
 )
 
-var _ http.Handler = (*handler)(nil)
+var _ Handler = (*handler)(nil)
+
+// Handler is an HTTP handler driven by lifecycle state updates.
+// Handlers could be mounted to health service with Service.Mount.
+type Handler interface {
+	http.Handler
+
+	// Update is called on each lifecycle state change.
+	Update(states []lifecycle.ServiceState)
+}
+
+// TransitionHandler is implemented by mounted handlers which track service transitions.
+// Health service delivers transitions without drops, transitions of each service are
+// delivered in order, while state updates are only latest snapshots of lifecycle state.
+type TransitionHandler interface {
+	// Transition is called on each service status change.
+	Transition(ev lifecycle.TransitionEvent)
+}
 
 type serviceState struct {
-	ID     int    `json:"id"`
-	Name   string `json:"name"`
-	Status string `json:"status"`
-	Error  string `json:"error,omitempty"`
+	ID            int           `json:"id"`
+	Name          string        `json:"name"`
+	Status        string        `json:"status"`
+	Error         string        `json:"error,omitempty"`
+	Criticality   string        `json:"criticality"`
+	Since         time.Time     `json:"since"`
+	StartDuration string        `json:"start_duration,omitempty"`
+	StopDuration  string        `json:"stop_duration,omitempty"`
+	Restarts      int           `json:"restarts"`
+	IgnoredErrors int           `json:"ignored_errors,omitempty"`
+	Reason        string        `json:"reason,omitempty"`
+	LastHeartbeat *time.Time    `json:"last_heartbeat,omitempty"`
+	NextRestart   *time.Time    `json:"next_restart,omitempty"`
+	Errors        []errorRecord `json:"errors,omitempty"`
+}
+
+type errorRecord struct {
+	Time  time.Time `json:"time"`
+	Error string    `json:"error"`
+}
+
+func newServiceState(st lifecycle.ServiceState) serviceState {
+	res := serviceState{
+		ID:            st.ID,
+		Name:          st.Name,
+		Status:        st.Status.String(),
+		Criticality:   st.Criticality.String(),
+		Since:         st.Since,
+		Restarts:      st.Restarts,
+		IgnoredErrors: st.IgnoredErrors,
+		Reason:        st.DegradedReason,
+	}
+	if !st.LastHeartbeat.IsZero() {
+		hb := st.LastHeartbeat
+		res.LastHeartbeat = &hb
+	}
+	if st.Error != nil {
+		res.Error = st.Error.Error()
+	}
+	if st.StartDuration > 0 {
+		res.StartDuration = st.StartDuration.String()
+	}
+	if st.StopDuration > 0 {
+		res.StopDuration = st.StopDuration.String()
+	}
+	if !st.NextRestart.IsZero() {
+		next := st.NextRestart
+		res.NextRestart = &next
+	}
+	for _, rec := range st.Errors {
+		res.Errors = append(res.Errors, errorRecord{Time: rec.Time, Error: rec.Error.Error()})
+	}
+	return res
 }
 
 type healthState struct {
 	Healthy  bool           `json:"healthy"`
+	Status   string         `json:"status"`
 	Services []serviceState `json:"services"`
 }
 
@@ -28,30 +95,21 @@ type handler struct {
 	statesMx sync.RWMutex
 }
 
-func (h *handler) update(states []lifecycle.ServiceState) {
+func (h *handler) Update(states []lifecycle.ServiceState) {
 	h.statesMx.Lock()
 	defer h.statesMx.Unlock()
 	h.states = states
 }
 
 func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	states := make([]serviceState, len(h.states))
-	healthy := true
 	h.statesMx.RLock()
+	states := make([]serviceState, len(h.states))
 	for i, st := range h.states {
-		states[i] = serviceState{
-			ID:     st.ID,
-			Name:   st.Name,
-			Status: st.Status.String(),
-		}
-		if st.Error != nil {
-			states[i].Error = st.Error.Error()
-		}
-		if st.Status == types.ServiceStatusError {
-			healthy = false
-		}
+		states[i] = newServiceState(st)
 	}
+	status := Aggregate(h.states)
 	h.statesMx.RUnlock()
+	healthy := status != StatusUnhealthy
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
@@ -62,6 +120,7 @@ func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	enc.SetIndent("", "  ")
 	if err := enc.Encode(healthState{
 		Healthy:  healthy,
+		Status:   status.String(),
 		Services: states,
 	}); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
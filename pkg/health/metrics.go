@@ -0,0 +1,232 @@
+package health
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+)
+
+var (
+	_ Handler           = (*Metrics)(nil)
+	_ TransitionHandler = (*Metrics)(nil)
+)
+
+// metricsStatuses is a list of all service statuses exported as metrics.
+var metricsStatuses = []types.ServiceStatus{
+	types.ServiceStatusInit,
+	types.ServiceStatusStarting,
+	types.ServiceStatusRunning,
+	types.ServiceStatusStopping,
+	types.ServiceStatusStopped,
+	types.ServiceStatusError,
+	types.ServiceStatusReady,
+	types.ServiceStatusDegraded,
+	types.ServiceStatusPaused,
+	types.ServiceStatusCompleted,
+}
+
+// Metrics exports lifecycle state in Prometheus text exposition format.
+// It's driven by lifecycle state updates and service transitions,
+// and could be mounted to health service:
+//
+//	hs.Mount("/metrics", health.NewMetrics())
+type Metrics struct {
+	services map[int]*serviceMetrics
+	mx       sync.Mutex
+}
+
+type serviceMetrics struct {
+	name   string
+	status types.ServiceStatus
+	since  time.Time
+
+	statusSeconds map[types.ServiceStatus]float64
+	restarts      uint64
+	runtimeErrors uint64
+	ignoredErrors uint64
+	starts        uint64
+	stops         uint64
+	lastStart     time.Duration
+	lastStop      time.Duration
+}
+
+// NewMetrics creates new metrics handler.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		services: make(map[int]*serviceMetrics),
+	}
+}
+
+// Update metrics with new lifecycle state.
+func (m *Metrics) Update(states []lifecycle.ServiceState) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	now := time.Now()
+	for _, st := range states {
+		m.service(st.ID, st.Name, now).ignoredErrors = uint64(st.IgnoredErrors)
+	}
+}
+
+// Transition updates status metrics and counters of the service.
+func (m *Metrics) Transition(ev lifecycle.TransitionEvent) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	m.service(ev.ID, ev.Service, ev.At).apply(ev)
+}
+
+// service returns metrics of the service, new service is created in init status.
+func (m *Metrics) service(id int, name string, now time.Time) *serviceMetrics {
+	svc, ok := m.services[id]
+	if !ok {
+		svc = &serviceMetrics{
+			name:          name,
+			status:        types.ServiceStatusInit,
+			since:         now,
+			statusSeconds: make(map[types.ServiceStatus]float64),
+		}
+		m.services[id] = svc
+	}
+	return svc
+}
+
+func (s *serviceMetrics) apply(ev lifecycle.TransitionEvent) {
+	s.statusSeconds[s.status] += ev.At.Sub(s.since).Seconds()
+	switch {
+	case ev.To == types.ServiceStatusStarting:
+		s.starts++
+		if ev.From == types.ServiceStatusError {
+			s.restarts++
+		}
+	case ev.From == types.ServiceStatusStarting && ev.To == types.ServiceStatusRunning:
+		s.lastStart = ev.At.Sub(s.since)
+	case ev.To == types.ServiceStatusStopping:
+		s.stops++
+	case ev.From == types.ServiceStatusStopping && ev.To == types.ServiceStatusStopped:
+		s.lastStop = ev.At.Sub(s.since)
+	case ev.From.Up() && ev.To == types.ServiceStatusError:
+		s.runtimeErrors++
+	}
+	s.status = ev.To
+	s.since = ev.At
+}
+
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	out := bufio.NewWriter(w)
+	m.write(out, time.Now())
+	if err := out.Flush(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (m *Metrics) write(out *bufio.Writer, now time.Time) {
+	m.mx.Lock()
+	defer m.mx.Unlock()
+
+	ids := make([]int, 0, len(m.services))
+	for id := range m.services {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	writeHeader(out, "lifecycle_service_status", "gauge",
+		"Current status of the service, 1 for current status and 0 for others.")
+	for _, id := range ids {
+		svc := m.services[id]
+		for _, st := range metricsStatuses {
+			var val float64
+			if svc.status == st {
+				val = 1
+			}
+			writeSample(out, "lifecycle_service_status", id, svc.name, st, val)
+		}
+	}
+	writeHeader(out, "lifecycle_service_status_seconds_total", "counter",
+		"Total time in seconds spent by the service in each status.")
+	for _, id := range ids {
+		svc := m.services[id]
+		for _, st := range metricsStatuses {
+			val := svc.statusSeconds[st]
+			if svc.status == st {
+				val += now.Sub(svc.since).Seconds()
+			}
+			writeSample(out, "lifecycle_service_status_seconds_total", id, svc.name, st, val)
+		}
+	}
+	for _, c := range []struct {
+		name, typ, help string
+		value           func(*serviceMetrics) float64
+	}{
+		{
+			"lifecycle_service_restarts_total", "counter", "Total number of service restarts after runtime errors.",
+			func(s *serviceMetrics) float64 { return float64(s.restarts) },
+		},
+		{
+			"lifecycle_service_runtime_errors_total", "counter", "Total number of service runtime errors.",
+			func(s *serviceMetrics) float64 { return float64(s.runtimeErrors) },
+		},
+		{
+			"lifecycle_service_ignored_errors_total", "counter", "Total number of ignorable service runtime errors.",
+			func(s *serviceMetrics) float64 { return float64(s.ignoredErrors) },
+		},
+		{
+			"lifecycle_service_starts_total", "counter", "Total number of service startups.",
+			func(s *serviceMetrics) float64 { return float64(s.starts) },
+		},
+		{
+			"lifecycle_service_stops_total", "counter", "Total number of service shutdowns.",
+			func(s *serviceMetrics) float64 { return float64(s.stops) },
+		},
+		{
+			"lifecycle_service_last_start_duration_seconds", "gauge", "Duration of the last service startup.",
+			func(s *serviceMetrics) float64 { return s.lastStart.Seconds() },
+		},
+		{
+			"lifecycle_service_last_stop_duration_seconds", "gauge", "Duration of the last service shutdown.",
+			func(s *serviceMetrics) float64 { return s.lastStop.Seconds() },
+		},
+	} {
+		writeHeader(out, c.name, c.typ, c.help)
+		for _, id := range ids {
+			svc := m.services[id]
+			writeSample(out, c.name, id, svc.name, -1, c.value(svc))
+		}
+	}
+}
+
+func writeHeader(out *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeSample writes single metric sample, status label is omitted if status is negative.
+func writeSample(out *bufio.Writer, name string, id int, service string, status types.ServiceStatus, val float64) {
+	out.WriteString(name)
+	out.WriteString(`{id="`)
+	out.WriteString(strconv.Itoa(id))
+	out.WriteString(`",service="`)
+	out.WriteString(escapeLabel(service))
+	if status >= 0 {
+		out.WriteString(`",status="`)
+		out.WriteString(status.String())
+	}
+	out.WriteString(`"} `)
+	out.WriteString(strconv.FormatFloat(val, 'g', -1, 64))
+	out.WriteByte('\n')
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,78 @@
+package health
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	m := NewMetrics()
+	m.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusInit, IgnoredErrors: 2}})
+	from := types.ServiceStatusInit
+	transition := func(to types.ServiceStatus, err error) {
+		m.Transition(lifecycle.TransitionEvent{ID: 0, Service: "web", From: from, To: to, Error: err, At: time.Now()})
+		from = to
+	}
+	transition(types.ServiceStatusStarting, nil)
+	transition(types.ServiceStatusRunning, nil)
+	transition(types.ServiceStatusError, errors.New("fail"))
+	transition(types.ServiceStatusStarting, nil)
+	transition(types.ServiceStatusRunning, nil)
+	// snapshot of lifecycle state doesn't change status metrics
+	m.Update([]lifecycle.ServiceState{{ID: 0, Name: "web", Status: types.ServiceStatusStarting, IgnoredErrors: 2}})
+
+	var buf bytes.Buffer
+	out := bufio.NewWriter(&buf)
+	m.write(out, time.Now())
+	require.NoError(t, out.Flush())
+	res := buf.String()
+	require.Contains(t, res, "# TYPE lifecycle_service_status gauge\n")
+	require.Contains(t, res, `lifecycle_service_status{id="0",service="web",status="Running"} 1`+"\n")
+	require.Contains(t, res, `lifecycle_service_status{id="0",service="web",status="Error"} 0`+"\n")
+	require.Contains(t, res, `lifecycle_service_restarts_total{id="0",service="web"} 1`+"\n")
+	require.Contains(t, res, `lifecycle_service_runtime_errors_total{id="0",service="web"} 1`+"\n")
+	require.Contains(t, res, `lifecycle_service_starts_total{id="0",service="web"} 2`+"\n")
+	require.Contains(t, res, `lifecycle_service_ignored_errors_total{id="0",service="web"} 2`+"\n")
+}
+
+func TestMetricsService(t *testing.T) {
+	lf := lifecycle.New(lifecycle.DefaultConfig)
+	t.Cleanup(func() { lf.Close() })
+	hs := NewService("127.0.0.1:0", lf)
+	m := NewMetrics()
+	hs.Mount("/metrics", m)
+	hs.RegisterLifecycle(lf)
+	var starts int32
+	lf.RegisterService(types.ServiceConfig{
+		Name: "worker",
+		StartupHook: func(_ context.Context, errCh chan<- error) error {
+			if atomic.AddInt32(&starts, 1) <= 5 {
+				go func() { errCh <- errors.New("fail") }()
+			}
+			return nil
+		},
+		RestartPolicy: types.ServiceRestartPolicy{RestartOnFailure: true},
+	})
+	require.NoError(t, lf.Start())
+	t.Cleanup(func() { lf.Stop() })
+	require.Eventually(t, func() bool {
+		var buf bytes.Buffer
+		out := bufio.NewWriter(&buf)
+		m.write(out, time.Now())
+		require.NoError(t, out.Flush())
+		return bytes.Contains(buf.Bytes(), []byte(`lifecycle_service_restarts_total{id="1",service="worker"} 5`+"\n"))
+	}, time.Second, time.Millisecond*10)
+}
+
+func TestEscapeLabel(t *testing.T) {
+	require.Equal(t, `a\"b\\c\nd`, escapeLabel("a\"b\\c\nd"))
+}
@@ -8,13 +8,25 @@ import (
 
 	"github.com/g4s8/go-lifecycle/pkg/adaptors"
 	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 	"github.com/g4s8/go-lifecycle/pkg/types"
 	"github.com/pkg/errors"
 )
 
+// ServiceName is a name of health service in lifecycle.
+const ServiceName = "health"
+
 // Lifecycle monitor provider
 type Lifecycle interface {
 	SubscribeMonitor(ch chan<- []lifecycle.ServiceState) lifecycle.Subscription
+	SubscribeTransitionsWith(ch chan<- lifecycle.TransitionEvent, cfg lifecycle.SubscriptionConfig,
+		filters ...lifecycle.TransitionFilter) lifecycle.Subscription
+}
+
+// transitionsSubscription delivers transitions to handlers without drops.
+var transitionsSubscription = lifecycle.SubscriptionConfig{
+	BufferSize: lifecycle.DefaultSubscriptionConfig.BufferSize,
+	Overflow:   lifecycle.OverflowBlock,
 }
 
 // Service starts HTTP server on given address.
@@ -22,8 +34,16 @@ type Service struct {
 	addr   string
 	lf     Lifecycle
 	stopCh chan struct{}
+	mounts []mount
+	logger logging.Logger
+
+	statesSub      lifecycle.Subscription
+	transitionsSub lifecycle.Subscription
+}
 
-	statesSub lifecycle.Subscription
+type mount struct {
+	pattern string
+	handler http.Handler
 }
 
 // NewService creates new health service.
@@ -32,13 +52,33 @@ func NewService(addr string, lf Lifecycle) *Service {
 		addr:   addr,
 		lf:     lf,
 		stopCh: make(chan struct{}),
+		logger: logging.Nop,
 	}
 }
 
+// loggerSetter is implemented by handlers which accept logger.
+type loggerSetter interface {
+	SetLogger(logger logging.Logger)
+}
+
+// SetLogger sets logger of the service and all mounted handlers which accept logger.
+// It should be called before service startup.
+func (s *Service) SetLogger(logger logging.Logger) {
+	s.logger = logger
+}
+
+// Mount registers additional handler on the pattern of health service.
+// If mounted handler implements Handler interface, it receives lifecycle state
+// updates same as health check handler, if it implements TransitionHandler, it receives
+// service transitions. It should be called before service startup.
+func (s *Service) Mount(pattern string, h http.Handler) {
+	s.mounts = append(s.mounts, mount{pattern: pattern, handler: h})
+}
+
 // RegisterLifecycle registers service in lifecycle manager.
 func (s *Service) RegisterLifecycle(lf adaptors.LifecycleRegistry) {
 	lf.RegisterService(types.ServiceConfig{
-		Name:         "health",
+		Name:         ServiceName,
 		StartupHook:  s.Start,
 		ShutdownHook: s.Stop,
 		RestartPolicy: types.ServiceRestartPolicy{
@@ -50,23 +90,48 @@ func (s *Service) RegisterLifecycle(lf adaptors.LifecycleRegistry) {
 
 func (s *Service) Start(ctx context.Context, errCh chan<- error) error {
 	h := &handler{}
+	mux := http.NewServeMux()
+	mux.Handle("/", h)
+	handlers := []Handler{h}
+	var trHandlers []TransitionHandler
+	for _, m := range s.mounts {
+		if ls, ok := m.handler.(loggerSetter); ok {
+			ls.SetLogger(s.logger)
+		}
+		mux.Handle(m.pattern, m.handler)
+		if h, ok := m.handler.(Handler); ok {
+			handlers = append(handlers, h)
+		}
+		if h, ok := m.handler.(TransitionHandler); ok {
+			trHandlers = append(trHandlers, h)
+		}
+	}
 	statesCh := make(chan []lifecycle.ServiceState)
+	transitionsCh := make(chan lifecycle.TransitionEvent)
 	go func() {
 		for {
 			select {
 			case next := <-statesCh:
-				h.update(next)
+				for _, h := range handlers {
+					h.Update(next)
+				}
+			case ev := <-transitionsCh:
+				for _, h := range trHandlers {
+					h.Transition(ev)
+				}
 			case <-s.stopCh:
 				close(statesCh)
+				close(transitionsCh)
 				return
 			}
 		}
 	}()
 	s.statesSub = s.lf.SubscribeMonitor(statesCh)
+	s.transitionsSub = s.lf.SubscribeTransitionsWith(transitionsCh, transitionsSubscription)
 
 	srv := &http.Server{
 		Addr:    s.addr,
-		Handler: h,
+		Handler: mux,
 	}
 	addr := srv.Addr
 	if addr == "" {
@@ -76,6 +141,7 @@ func (s *Service) Start(ctx context.Context, errCh chan<- error) error {
 	if err != nil {
 		return errors.Wrap(err, "listen tcp: %w")
 	}
+	s.logger.Log(logging.LevelInfo, "health service listening", logging.String("addr", ln.Addr().String()))
 	go func() {
 		if err := srv.Serve(ln); err != nil {
 			if err != http.ErrServerClosed {
@@ -90,6 +156,8 @@ func (s *Service) Start(ctx context.Context, errCh chan<- error) error {
 func (s *Service) Stop(ctx context.Context) error {
 	s.statesSub.Cancel()
 	s.statesSub = nil
+	s.transitionsSub.Cancel()
+	s.transitionsSub = nil
 	close(s.stopCh)
 	return nil
 }
@@ -0,0 +1,54 @@
+package health
+
+import (
+	"strconv"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+)
+
+// Status is an aggregated health status of lifecycle services.
+type Status int
+
+const (
+	// StatusHealthy means that no service has failed.
+	StatusHealthy Status = iota
+	// StatusDegraded means that only optional services have failed
+	// or some services reported degradation.
+	StatusDegraded
+	// StatusUnhealthy means that at least one critical service has failed.
+	StatusUnhealthy
+)
+
+func (s Status) String() string {
+	switch s {
+	case StatusHealthy:
+		return "healthy"
+	case StatusDegraded:
+		return "degraded"
+	case StatusUnhealthy:
+		return "unhealthy"
+	default:
+		return "Status(" + strconv.Itoa(int(s)) + ")"
+	}
+}
+
+// Aggregate services states into health status.
+func Aggregate(states []lifecycle.ServiceState) Status {
+	res := StatusHealthy
+	for _, st := range states {
+		if st.Status == types.ServiceStatusDegraded {
+			res = StatusDegraded
+			continue
+		}
+		if st.Status != types.ServiceStatusError {
+			continue
+		}
+		if st.Criticality == types.ServiceOptional {
+			res = StatusDegraded
+			continue
+		}
+		return StatusUnhealthy
+	}
+	return res
+}
@@ -0,0 +1,51 @@
+package health
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/g4s8/go-lifecycle/pkg/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate(t *testing.T) {
+	failed := errors.New("failed")
+	running := lifecycle.ServiceState{ID: 0, Name: "web", Status: types.ServiceStatusRunning}
+	optionalErr := lifecycle.ServiceState{
+		ID: 1, Name: "pusher", Status: types.ServiceStatusError,
+		Error: failed, Criticality: types.ServiceOptional,
+	}
+	criticalErr := lifecycle.ServiceState{ID: 2, Name: "db", Status: types.ServiceStatusError, Error: failed}
+	degraded := lifecycle.ServiceState{ID: 3, Name: "queue", Status: types.ServiceStatusDegraded, DegradedReason: "lag"}
+	paused := lifecycle.ServiceState{ID: 4, Name: "consumer", Status: types.ServiceStatusPaused}
+	for _, tc := range []struct {
+		name   string
+		states []lifecycle.ServiceState
+		status Status
+		code   int
+	}{
+		{"healthy", []lifecycle.ServiceState{running}, StatusHealthy, http.StatusOK},
+		{"degraded", []lifecycle.ServiceState{running, optionalErr}, StatusDegraded, http.StatusOK},
+		{"reported degraded", []lifecycle.ServiceState{running, degraded}, StatusDegraded, http.StatusOK},
+		{"paused", []lifecycle.ServiceState{running, paused}, StatusHealthy, http.StatusOK},
+		{"unhealthy", []lifecycle.ServiceState{running, optionalErr, criticalErr}, StatusUnhealthy,
+			http.StatusServiceUnavailable},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.status, Aggregate(tc.states))
+
+			h := &handler{}
+			h.Update(tc.states)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+			require.Equal(t, tc.code, rec.Code)
+			var rsp healthState
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &rsp))
+			require.Equal(t, tc.status.String(), rsp.Status)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package lifecycle
+
+import (
+	"context"
+	"sync"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+)
+
+// batch is a state of lifecycle batch mode.
+type batch struct {
+	once   sync.Once
+	doneCh chan struct{}
+	err    error
+}
+
+// Done returns a channel which is closed when lifecycle is stopped in batch mode
+// after all jobs are finished, the channel is never closed if batch mode is disabled.
+func (l *Lifecycle) Done() <-chan struct{} {
+	return l.batch.doneCh
+}
+
+// Err returns BatchError if any job failed or lifecycle failed to stop
+// in batch mode, it returns nil until Done channel is closed.
+func (l *Lifecycle) Err() error {
+	select {
+	case <-l.batch.doneCh:
+		return l.batch.err
+	default:
+		return nil
+	}
+}
+
+// checkBatch stops lifecycle in batch mode if all jobs are finished.
+func (l *Lifecycle) checkBatch() {
+	if !l.config.Batch {
+		return
+	}
+	jobs, finished := l.jobResults()
+	if !finished {
+		return
+	}
+	l.batch.once.Do(func() {
+		go l.finishBatch(jobs)
+	})
+}
+
+// jobResults returns outcomes of all jobs and true if all jobs are
+// completed or failed without restart after lifecycle was started.
+func (l *Lifecycle) jobResults() ([]ServiceResult, bool) {
+	l.stateMx.RLock()
+	defer l.stateMx.RUnlock()
+
+	if !l.started {
+		return nil, false
+	}
+	var jobs []ServiceResult
+	for i, cfg := range l.configs {
+		if cfg.Job == nil {
+			continue
+		}
+		st := l.states[i]
+		res := ServiceResult{ID: i, Service: cfg.Name, Outcome: OutcomeCompleted}
+		switch {
+		case st.Status == types.ServiceStatusCompleted:
+		case st.Status == types.ServiceStatusError && st.Final:
+			res.Outcome = OutcomeFailed
+			res.Error = st.Error
+		default:
+			return nil, false
+		}
+		jobs = append(jobs, res)
+	}
+	return jobs, true
+}
+
+func (l *Lifecycle) finishBatch(jobs []ServiceResult) {
+	l.config.Logger.Log(logging.LevelInfo, "all jobs finished, stopping lifecycle",
+		logging.Int("jobs", len(jobs)))
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
+	defer cancel()
+	_, stopErr := l.stop(ctx, PhaseShutdown, "jobs finished")
+	failed := stopErr != nil
+	for _, j := range jobs {
+		failed = failed || j.Error != nil
+	}
+	if failed {
+		l.batch.err = &BatchError{Jobs: jobs, Stop: stopErr}
+	}
+	close(l.batch.doneCh)
+}
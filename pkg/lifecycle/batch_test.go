@@ -0,0 +1,86 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBatch(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Batch = true
+	lf := newTestLifecycle(t, cfg)
+	var migrated int32
+	lf.RegisterService(types.JobService("migrations", func(context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		atomic.StoreInt32(&migrated, 1)
+		return nil
+	}))
+	var startedAfterMigrations int32
+	lf.RegisterService(types.ServiceConfig{
+		Name:             "cache",
+		DependsOn:        []string{"migrations"},
+		WaitDependencies: true,
+		Job: func(context.Context) error {
+			atomic.StoreInt32(&startedAfterMigrations, atomic.LoadInt32(&migrated))
+			return nil
+		},
+	})
+	require.NoError(t, lf.Start())
+	select {
+	case <-lf.Done():
+	case <-time.After(time.Second):
+		t.Fatal("batch is not done")
+	}
+	require.NoError(t, lf.Err())
+	require.Equal(t, int32(1), atomic.LoadInt32(&startedAfterMigrations))
+	require.Eventually(t, func() bool {
+		for _, st := range lf.Statuses() {
+			if st.Status != types.ServiceStatusStopped {
+				return false
+			}
+		}
+		return true
+	}, time.Second, time.Millisecond)
+}
+
+func TestBatchJobFailed(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Batch = true
+	lf := newTestLifecycle(t, cfg)
+	targetErr := errors.New("job failed")
+	lf.RegisterService(types.ServiceConfig{
+		Name: "migrations",
+		Job:  func(context.Context) error { return targetErr },
+	})
+	require.NoError(t, lf.Start())
+	select {
+	case <-lf.Done():
+	case <-time.After(time.Second):
+		t.Fatal("batch is not done")
+	}
+	var batchErr *BatchError
+	require.ErrorAs(t, lf.Err(), &batchErr)
+	require.ErrorIs(t, batchErr, targetErr)
+	require.Equal(t, OutcomeFailed, batchErr.Jobs[0].Outcome)
+	require.Never(t, func() bool {
+		return lf.Statuses()[0].Status != types.ServiceStatusError
+	}, time.Millisecond*20, time.Millisecond, "failed job should keep its status")
+	st := lf.Statuses()[0]
+	require.True(t, st.Final)
+	require.ErrorIs(t, st.Error, targetErr)
+}
+
+func TestBatchWithoutJobs(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.Batch = true
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("web", func(context.Context, chan<- error) error { return nil })
+	require.ErrorIs(t, lf.Start(), ErrNoJobs)
+	require.Equal(t, types.ServiceStatusInit, lf.Statuses()[0].Status)
+}
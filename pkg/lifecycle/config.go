@@ -2,6 +2,9 @@ package lifecycle
 
 import (
 	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/tracing"
+	"github.com/g4s8/go-lifecycle/pkg/types"
 )
 
 // StartStrategy is a strategy for startup.
@@ -28,6 +31,28 @@ type Config struct {
 	ShutdownTimeout time.Duration
 	// StartStrategy is a strategy for startup.
 	StartStrategy StartStrategy
+	// ErrorsHistorySize is a number of last errors kept in service state.
+	ErrorsHistorySize int
+	// Logger for lifecycle messages, NopLogger is used if not set.
+	Logger Logger
+	// Journal is an optional sink for lifecycle and service transitions.
+	Journal *Journal
+	// SlowStartupThreshold enables startup report logging
+	// if startup takes longer than the threshold.
+	SlowStartupThreshold time.Duration
+	// Middlewares are applied to hooks of all registered services, they
+	// wrap service middlewares, the first one is the outermost.
+	Middlewares []types.Middleware
+	// Tracer traces lifecycle phases, service hooks and restarts if set.
+	Tracer tracing.Tracer
+	// CrashLoop enables crash-loop protection across process restarts if set.
+	CrashLoop *CrashLoopConfig
+	// WaitReady makes Start wait until all started services are ready or degraded,
+	// the wait is bounded by the startup timeout.
+	WaitReady bool
+	// Batch enables batch mode: lifecycle is stopped automatically once all
+	// jobs are completed or failed, see Lifecycle.Done. At least one job is required.
+	Batch bool
 }
 
 func (c *Config) check() {
@@ -40,11 +65,26 @@ func (c *Config) check() {
 	if c.StartStrategy == 0 {
 		c.StartStrategy = DefaultConfig.StartStrategy
 	}
+	if c.ErrorsHistorySize <= 0 {
+		c.ErrorsHistorySize = DefaultConfig.ErrorsHistorySize
+	}
+	if c.Logger == nil {
+		c.Logger = NopLogger
+	}
+	if c.Tracer == nil {
+		c.Tracer = tracing.Nop
+	}
+	if c.CrashLoop != nil {
+		crashLoop := *c.CrashLoop
+		crashLoop.check()
+		c.CrashLoop = &crashLoop
+	}
 }
 
 // DefaultConfig is a default lifecycle configuration.
 var DefaultConfig = Config{
-	StartupTimeout:  5 * time.Second,
-	ShutdownTimeout: 5 * time.Second,
-	StartStrategy:   StartStrategyFailFast | StartStrategyRollbackOnError,
+	StartupTimeout:    5 * time.Second,
+	ShutdownTimeout:   5 * time.Second,
+	StartStrategy:     StartStrategyFailFast | StartStrategyRollbackOnError,
+	ErrorsHistorySize: 5,
 }
@@ -12,4 +12,5 @@ func TestDefaultConfig(t *testing.T) {
 	require.Equal(t, DefaultConfig.StartupTimeout, cfg.StartupTimeout)
 	require.Equal(t, DefaultConfig.ShutdownTimeout, cfg.ShutdownTimeout)
 	require.Equal(t, DefaultConfig.StartStrategy, cfg.StartStrategy)
+	require.Equal(t, DefaultConfig.ErrorsHistorySize, cfg.ErrorsHistorySize)
 }
@@ -0,0 +1,209 @@
+package lifecycle
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// CrashLoopConfig is a configuration of crash-loop protection.
+//
+// Lifecycle records startup outcomes of services in the state file, so it
+// can detect that a service failed at startup many times in a row even if
+// the process was restarted between attempts. The failure is counted before
+// the service is started, so the process crash during startup is counted too.
+type CrashLoopConfig struct {
+	// StatePath is a path of the state file, it's required.
+	StatePath string
+	// Threshold is a number of consecutive failed startups to detect crash loop.
+	Threshold int
+	// Window is a period of time after the last failed startup when failures
+	// are counted, failures counter is reset if the last attempt is older.
+	Window time.Duration
+	// Backoff is a startup delay when crash loop is detected,
+	// it's doubled on each next failed startup.
+	Backoff time.Duration
+	// MaxBackoff is a maximum startup delay.
+	MaxBackoff time.Duration
+	// SkipOptional skips optional services in crash loop instead of delaying startup.
+	SkipOptional bool
+}
+
+// DefaultCrashLoopConfig is a default crash-loop protection configuration without state path.
+var DefaultCrashLoopConfig = CrashLoopConfig{
+	Threshold:  3,
+	Window:     10 * time.Minute,
+	Backoff:    time.Second,
+	MaxBackoff: time.Minute,
+}
+
+func (c *CrashLoopConfig) check() {
+	if c.Threshold <= 0 {
+		c.Threshold = DefaultCrashLoopConfig.Threshold
+	}
+	if c.Window <= 0 {
+		c.Window = DefaultCrashLoopConfig.Window
+	}
+	if c.Backoff <= 0 {
+		c.Backoff = DefaultCrashLoopConfig.Backoff
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = DefaultCrashLoopConfig.MaxBackoff
+	}
+	if c.MaxBackoff < c.Backoff {
+		c.MaxBackoff = c.Backoff
+	}
+}
+
+type crashLoopRecord struct {
+	Failures    int       `json:"failures"`
+	LastAttempt time.Time `json:"last_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+}
+
+// crashLoopGuard tracks startup outcomes in the state file,
+// nil guard is a no-op guard.
+type crashLoopGuard struct {
+	cfg      CrashLoopConfig
+	logger   Logger
+	services map[string]*crashLoopRecord
+}
+
+// loadCrashLoopGuard loads crash-loop state from the state file,
+// it returns nil if crash-loop protection is not configured.
+func loadCrashLoopGuard(cfg *CrashLoopConfig, logger Logger) *crashLoopGuard {
+	if cfg == nil {
+		return nil
+	}
+	g := &crashLoopGuard{
+		cfg:      *cfg,
+		logger:   logger,
+		services: make(map[string]*crashLoopRecord),
+	}
+	data, err := os.ReadFile(cfg.StatePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logger.Log(logging.LevelError, "failed to read crash loop state", logging.Err(err))
+		}
+		return g
+	}
+	if err := json.Unmarshal(data, &g.services); err != nil {
+		logger.Log(logging.LevelError, "failed to decode crash loop state", logging.Err(err))
+		g.services = make(map[string]*crashLoopRecord)
+	}
+	return g
+}
+
+// failures returns number of consecutive failed startups of the service.
+func (g *crashLoopGuard) failures(name string, now time.Time) int {
+	rec, ok := g.services[name]
+	if !ok || now.Sub(rec.LastAttempt) > g.cfg.Window {
+		return 0
+	}
+	return rec.Failures
+}
+
+// plan startup of services: it returns startup delay and
+// the set of service indexes which should be skipped.
+func (g *crashLoopGuard) plan(configs []types.ServiceConfig, now time.Time) (time.Duration, map[int]bool) {
+	if g == nil {
+		return 0, nil
+	}
+	var delay time.Duration
+	skip := make(map[int]bool)
+	for i, cfg := range configs {
+		n := g.failures(cfg.Name, now)
+		if n < g.cfg.Threshold {
+			continue
+		}
+		if g.cfg.SkipOptional && cfg.Criticality == types.ServiceOptional {
+			g.logger.Log(logging.LevelWarn, "service is in crash loop, skipping",
+				logging.String("service", cfg.Name), logging.Int("failures", n))
+			skip[i] = true
+			continue
+		}
+		if d := g.backoff(n); d > delay {
+			delay = d
+		}
+	}
+	if delay > 0 {
+		g.logger.Log(logging.LevelWarn, "crash loop detected, delaying startup", logging.Any("delay", delay))
+	}
+	return delay, skip
+}
+
+func (g *crashLoopGuard) backoff(failures int) time.Duration {
+	delay := g.cfg.Backoff
+	for i := g.cfg.Threshold; i < failures && delay < g.cfg.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > g.cfg.MaxBackoff {
+		delay = g.cfg.MaxBackoff
+	}
+	return delay
+}
+
+// begin counts startup of the service as failed until it's finished.
+func (g *crashLoopGuard) begin(name string, now time.Time) {
+	if g == nil {
+		return
+	}
+	failures := g.failures(name, now)
+	rec, ok := g.services[name]
+	if !ok {
+		rec = new(crashLoopRecord)
+		g.services[name] = rec
+	}
+	rec.Failures = failures + 1
+	rec.LastAttempt = now
+	g.save()
+}
+
+// end records startup outcome of the service, successful startup resets failures counter.
+func (g *crashLoopGuard) end(name string, err error) {
+	if g == nil {
+		return
+	}
+	if err == nil {
+		delete(g.services, name)
+	} else if rec, ok := g.services[name]; ok {
+		rec.LastError = err.Error()
+	}
+	g.save()
+}
+
+// save state file atomically.
+func (g *crashLoopGuard) save() {
+	if err := g.write(); err != nil {
+		g.logger.Log(logging.LevelError, "failed to write crash loop state", logging.Err(err))
+	}
+}
+
+func (g *crashLoopGuard) write() error {
+	data, err := json.Marshal(g.services)
+	if err != nil {
+		return errors.Wrap(err, "encode state")
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(g.cfg.StatePath), filepath.Base(g.cfg.StatePath)+".tmp*")
+	if err != nil {
+		return errors.Wrap(err, "create temp file")
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "write temp file")
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return errors.Wrap(err, "sync temp file")
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "close temp file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), g.cfg.StatePath), "replace state file")
+}
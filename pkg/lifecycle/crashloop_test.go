@@ -0,0 +1,126 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashLoopBackoff(t *testing.T) {
+	g := &crashLoopGuard{cfg: CrashLoopConfig{
+		Threshold:  2,
+		Backoff:    time.Second,
+		MaxBackoff: 5 * time.Second,
+	}}
+	require.Equal(t, time.Second, g.backoff(2))
+	require.Equal(t, 2*time.Second, g.backoff(3))
+	require.Equal(t, 4*time.Second, g.backoff(4))
+	require.Equal(t, 5*time.Second, g.backoff(5))
+	require.Equal(t, 5*time.Second, g.backoff(100))
+}
+
+func TestCrashLoopEmptyStatePath(t *testing.T) {
+	cfg := DefaultConfig
+	crashLoop := DefaultCrashLoopConfig
+	cfg.CrashLoop = &crashLoop
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("web", func(context.Context, chan<- error) error { return nil })
+	err := lf.Start()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.ErrorIs(t, err, ErrInvalidConfig)
+	require.Equal(t, types.ServiceStatusInit, lf.Statuses()[0].Status)
+}
+
+func TestCrashLoopAcrossRestarts(t *testing.T) {
+	crashLoop := &CrashLoopConfig{
+		StatePath:    filepath.Join(t.TempDir(), "state.json"),
+		Threshold:    2,
+		Backoff:      time.Millisecond * 50,
+		SkipOptional: true,
+	}
+	targetErr := errors.New("startup failed")
+	var attempts, optionalAttempts int
+	run := func(fail bool) (time.Duration, error) {
+		cfg := DefaultConfig
+		cfg.StartStrategy = StartStrategyStartAll
+		cfg.CrashLoop = crashLoop
+		lf := newTestLifecycle(t, cfg)
+		lf.RegisterService(types.ServiceConfig{
+			Name:        "optional",
+			Criticality: types.ServiceOptional,
+			StartupHook: func(context.Context, chan<- error) error {
+				optionalAttempts++
+				return targetErr
+			},
+		})
+		lf.RegisterStartupHook("critical", func(context.Context, chan<- error) error {
+			attempts++
+			if fail {
+				return targetErr
+			}
+			return nil
+		})
+		start := time.Now()
+		err := lf.Start()
+		return time.Since(start), err
+	}
+
+	for i := 0; i < 2; i++ {
+		elapsed, err := run(true)
+		require.ErrorIs(t, err, targetErr)
+		require.Less(t, elapsed, crashLoop.Backoff)
+	}
+	require.Equal(t, 2, optionalAttempts)
+
+	elapsed, err := run(true)
+	require.ErrorIs(t, err, targetErr)
+	require.GreaterOrEqual(t, elapsed, crashLoop.Backoff)
+	require.Equal(t, 2, optionalAttempts, "optional service in crash loop should be skipped")
+
+	elapsed, err = run(false)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, elapsed, crashLoop.Backoff*2)
+	require.Equal(t, 4, attempts)
+
+	elapsed, err = run(false)
+	require.NoError(t, err)
+	require.Less(t, elapsed, crashLoop.Backoff)
+}
+
+func TestCrashLoopBackoffInterrupted(t *testing.T) {
+	crashLoop := &CrashLoopConfig{
+		StatePath: filepath.Join(t.TempDir(), "state.json"),
+		Threshold: 1,
+		Backoff:   time.Minute,
+	}
+	targetErr := errors.New("startup failed")
+	cfg := DefaultConfig
+	cfg.CrashLoop = crashLoop
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("svc", func(context.Context, chan<- error) error { return targetErr })
+	require.ErrorIs(t, lf.Start(), targetErr)
+
+	var started bool
+	lf = newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("svc", func(context.Context, chan<- error) error {
+		started = true
+		return nil
+	})
+	done := make(chan error, 1)
+	go func() { done <- lf.Start() }()
+	time.Sleep(time.Millisecond * 20)
+	require.NoError(t, lf.Stop())
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrStartupInterrupted)
+	case <-time.After(time.Second):
+		t.Fatal("crash-loop backoff is not interrupted by stop")
+	}
+	require.False(t, started, "services should not be started after stop")
+}
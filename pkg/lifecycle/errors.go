@@ -0,0 +1,208 @@
+package lifecycle
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrStartupTimeout is matched by StartError if startup timeout exceeded.
+	ErrStartupTimeout = errors.New("startup timeout")
+	// ErrShutdownTimeout is matched by StopError if shutdown timeout exceeded.
+	ErrShutdownTimeout = errors.New("shutdown timeout")
+	// ErrNotReady is matched by StartError if service or its dependency
+	// was not ready before startup timeout.
+	ErrNotReady = errors.New("service is not ready")
+	// ErrStartupInterrupted is returned by Lifecycle.Start if lifecycle
+	// was stopped during crash-loop backoff before startup.
+	ErrStartupInterrupted = errors.New("startup interrupted")
+)
+
+// ServiceOutcome is an outcome of the service on lifecycle startup or shutdown.
+type ServiceOutcome string
+
+// Service outcomes.
+const (
+	// OutcomeStarted service was started successfully.
+	OutcomeStarted ServiceOutcome = "started"
+	// OutcomeFailed service startup failed.
+	OutcomeFailed ServiceOutcome = "failed"
+	// OutcomeSkipped service was skipped by crash-loop protection.
+	OutcomeSkipped ServiceOutcome = "skipped"
+	// OutcomeNotStarted service was not started due to previous errors or timeout.
+	OutcomeNotStarted ServiceOutcome = "not started"
+	// OutcomeRolledBack service was started and then stopped on rollback.
+	OutcomeRolledBack ServiceOutcome = "rolled back"
+	// OutcomeRollbackFailed service was started, but failed to stop on rollback.
+	OutcomeRollbackFailed ServiceOutcome = "rollback failed"
+	// OutcomeStopped service was stopped successfully.
+	OutcomeStopped ServiceOutcome = "stopped"
+	// OutcomeStopFailed service failed to stop.
+	OutcomeStopFailed ServiceOutcome = "stop failed"
+	// OutcomeCompleted job was completed successfully.
+	OutcomeCompleted ServiceOutcome = "completed"
+)
+
+// ServiceResult is an outcome of the service with an error, if any.
+type ServiceResult struct {
+	// ID of the service.
+	ID int
+	// Service name.
+	Service string
+	// Outcome of the service.
+	Outcome ServiceOutcome
+	// Error of the service startup or shutdown.
+	Error error
+}
+
+// StartError is returned by Lifecycle.Start if any service failed to start.
+// It matches errors of all services and ErrStartupTimeout with errors.Is and errors.As.
+type StartError struct {
+	// Services outcomes in the order of registration.
+	Services []ServiceResult
+	// Timeout is true if startup timeout exceeded.
+	Timeout bool
+}
+
+func (e *StartError) Error() string {
+	return formatLifecycleError("lifecycle startup failed", e.Timeout, ErrStartupTimeout, e.Services)
+}
+
+// Is reports whether any service error matches target or
+// target is ErrStartupTimeout and startup timeout exceeded.
+func (e *StartError) Is(target error) bool {
+	if e.Timeout && target == ErrStartupTimeout {
+		return true
+	}
+	return resultsIs(e.Services, target)
+}
+
+// As finds the first service error that matches target.
+func (e *StartError) As(target interface{}) bool {
+	return resultsAs(e.Services, target)
+}
+
+// StopError is returned by Lifecycle.Stop if any service failed to stop.
+// It matches errors of all services and ErrShutdownTimeout with errors.Is and errors.As.
+type StopError struct {
+	// Services outcomes in the order of shutdown.
+	Services []ServiceResult
+	// Timeout is true if shutdown timeout exceeded.
+	Timeout bool
+}
+
+func (e *StopError) Error() string {
+	return formatLifecycleError("lifecycle shutdown failed", e.Timeout, ErrShutdownTimeout, e.Services)
+}
+
+// Is reports whether any service error matches target or
+// target is ErrShutdownTimeout and shutdown timeout exceeded.
+func (e *StopError) Is(target error) bool {
+	if e.Timeout && target == ErrShutdownTimeout {
+		return true
+	}
+	return resultsIs(e.Services, target)
+}
+
+// As finds the first service error that matches target.
+func (e *StopError) As(target interface{}) bool {
+	return resultsAs(e.Services, target)
+}
+
+// BatchError is returned by Lifecycle.Err if any job failed in batch mode.
+// It matches errors of failed jobs and batch shutdown error with errors.Is and errors.As.
+type BatchError struct {
+	// Jobs outcomes in the order of registration.
+	Jobs []ServiceResult
+	// Stop is an error of lifecycle shutdown, if any.
+	Stop error
+}
+
+func (e *BatchError) Error() string {
+	msg := formatLifecycleError("batch failed", false, nil, e.Jobs)
+	if e.Stop != nil {
+		msg += ": " + e.Stop.Error()
+	}
+	return msg
+}
+
+// Is reports whether any job error or shutdown error matches target.
+func (e *BatchError) Is(target error) bool {
+	return resultsIs(e.Jobs, target) || (e.Stop != nil && errors.Is(e.Stop, target))
+}
+
+// As finds the first job error or shutdown error that matches target.
+func (e *BatchError) As(target interface{}) bool {
+	return resultsAs(e.Jobs, target) || (e.Stop != nil && errors.As(e.Stop, target))
+}
+
+func resultsIs(results []ServiceResult, target error) bool {
+	for _, r := range results {
+		if r.Error != nil && errors.Is(r.Error, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func resultsAs(results []ServiceResult, target interface{}) bool {
+	for _, r := range results {
+		if r.Error != nil && errors.As(r.Error, target) {
+			return true
+		}
+	}
+	return false
+}
+
+func formatLifecycleError(prefix string, timeout bool, timeoutErr error, results []ServiceResult) string {
+	var parts []string
+	if timeout {
+		parts = append(parts, timeoutErr.Error())
+	}
+	for _, r := range results {
+		if r.Error != nil {
+			parts = append(parts, "service "+strconv.Quote(r.Service)+" "+string(r.Outcome)+": "+r.Error.Error())
+		}
+	}
+	if len(parts) == 0 {
+		return prefix
+	}
+	return prefix + ": " + strings.Join(parts, "; ")
+}
+
+// ValidationError is returned by Lifecycle.Validate and Lifecycle.Start if services
+// configuration is invalid. It matches all problems with errors.Is and errors.As.
+type ValidationError struct {
+	// Problems of services configuration in the order of registration.
+	Problems []error
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		parts[i] = p.Error()
+	}
+	return "invalid lifecycle configuration: " + strings.Join(parts, "; ")
+}
+
+// Is reports whether any problem matches target.
+func (e *ValidationError) Is(target error) bool {
+	for _, p := range e.Problems {
+		if errors.Is(p, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// As finds the first problem that matches target.
+func (e *ValidationError) As(target interface{}) bool {
+	for _, p := range e.Problems {
+		if errors.As(p, target) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStartError(t *testing.T) {
+	targetErr := errors.New("start failed")
+	stopErr := errors.New("stop failed")
+	lf := newTestLifecycle(t, DefaultConfig)
+	lf.RegisterService(types.ServiceConfig{
+		Name:         "first",
+		StartupHook:  func(context.Context, chan<- error) error { return nil },
+		ShutdownHook: func(context.Context) error { return stopErr },
+	})
+	lf.RegisterStartupHook("second", func(context.Context, chan<- error) error { return nil })
+	lf.RegisterStartupHook("third", func(context.Context, chan<- error) error { return targetErr })
+	lf.RegisterStartupHook("fourth", func(context.Context, chan<- error) error { return nil })
+
+	err := lf.Start()
+	var startErr *StartError
+	require.ErrorAs(t, err, &startErr)
+	require.ErrorIs(t, err, targetErr)
+	require.ErrorIs(t, err, stopErr)
+	require.NotErrorIs(t, err, ErrStartupTimeout)
+	outcomes := make([]ServiceOutcome, len(startErr.Services))
+	for i, r := range startErr.Services {
+		require.Equal(t, i, r.ID)
+		outcomes[i] = r.Outcome
+	}
+	require.Equal(t, []ServiceOutcome{
+		OutcomeRollbackFailed, OutcomeRolledBack, OutcomeFailed, OutcomeNotStarted,
+	}, outcomes)
+	require.Contains(t, err.Error(), `service "third" failed: start service: start failed`)
+}
+
+func TestStartErrorTimeout(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.StartupTimeout = 10 * time.Millisecond
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("slow", func(ctx context.Context, _ chan<- error) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	lf.RegisterStartupHook("next", func(context.Context, chan<- error) error { return nil })
+
+	err := lf.Start()
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	var startErr *StartError
+	require.ErrorAs(t, err, &startErr)
+	require.True(t, startErr.Timeout)
+	require.Equal(t, OutcomeNotStarted, startErr.Services[1].Outcome)
+}
+
+func TestStopError(t *testing.T) {
+	stopErr := errors.New("stop failed")
+	lf := newTestLifecycle(t, DefaultConfig)
+	lf.RegisterShutdownHook("first", func(context.Context) error { return nil })
+	lf.RegisterShutdownHook("second", func(context.Context) error { return stopErr })
+	require.NoError(t, lf.Start())
+
+	err := lf.Stop()
+	var e *StopError
+	require.ErrorAs(t, err, &e)
+	require.ErrorIs(t, err, stopErr)
+	require.False(t, e.Timeout)
+	require.Equal(t, []ServiceResult{
+		{ID: 1, Service: "second", Outcome: OutcomeStopFailed, Error: e.Services[0].Error},
+		{ID: 0, Service: "first", Outcome: OutcomeStopped},
+	}, e.Services)
+}
@@ -0,0 +1,66 @@
+package lifecycle
+
+import (
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+)
+
+// TransitionEvent is a single service status transition.
+type TransitionEvent struct {
+	// Service name, specified by user.
+	Service string
+	// Service ID, is assigned on register calls.
+	ID int
+	// From is a previous status of the service.
+	From types.ServiceStatus
+	// To is a new status of the service.
+	To types.ServiceStatus
+	// Error of the service, if any.
+	Error error
+	// At is a time of the transition.
+	At time.Time
+	// Attempt is a number of restart attempts of the service.
+	Attempt int
+}
+
+// TransitionFilter selects transition events delivered to subscriber.
+type TransitionFilter func(TransitionEvent) bool
+
+// ForServices selects transitions of services with specified names.
+func ForServices(names ...string) TransitionFilter {
+	return func(ev TransitionEvent) bool {
+		for _, name := range names {
+			if ev.Service == name {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ToStatuses selects transitions to specified statuses.
+func ToStatuses(statuses ...types.ServiceStatus) TransitionFilter {
+	return func(ev TransitionEvent) bool {
+		for _, st := range statuses {
+			if ev.To == st {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func matchAll(filters []TransitionFilter) func(TransitionEvent) bool {
+	if len(filters) == 0 {
+		return nil
+	}
+	return func(ev TransitionEvent) bool {
+		for _, f := range filters {
+			if !f(ev) {
+				return false
+			}
+		}
+		return true
+	}
+}
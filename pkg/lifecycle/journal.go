@@ -0,0 +1,324 @@
+package lifecycle
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// Phase is a phase of lifecycle manager.
+type Phase string
+
+// Lifecycle phases.
+const (
+	// PhaseStartup is a phase of starting all services.
+	PhaseStartup Phase = "startup"
+	// PhaseShutdown is a phase of stopping all services.
+	PhaseShutdown Phase = "shutdown"
+	// PhaseRollback is a phase of stopping started services on startup error.
+	PhaseRollback Phase = "rollback"
+)
+
+// JournalKind is a kind of journal record.
+type JournalKind string
+
+// Journal record kinds.
+const (
+	// JournalLifecycle is a lifecycle phase record.
+	JournalLifecycle JournalKind = "lifecycle"
+	// JournalService is a service transition record.
+	JournalService JournalKind = "service"
+)
+
+// JournalStage is a stage of lifecycle phase.
+type JournalStage string
+
+// Lifecycle phase stages.
+const (
+	JournalBegin JournalStage = "begin"
+	JournalEnd   JournalStage = "end"
+)
+
+// JournalRecord is a single journal entry, it's either lifecycle phase record
+// or service transition record depends on its kind.
+type JournalRecord struct {
+	// Time of the record.
+	Time time.Time `json:"time"`
+	// Kind of the record.
+	Kind JournalKind `json:"kind"`
+
+	// Phase of lifecycle for lifecycle records.
+	Phase Phase `json:"phase,omitempty"`
+	// Stage of the phase for lifecycle records.
+	Stage JournalStage `json:"stage,omitempty"`
+	// Reason of the phase, e.g. shutdown reason.
+	Reason string `json:"reason,omitempty"`
+
+	// Service name for service records.
+	Service string `json:"service,omitempty"`
+	// From is a previous status of the service.
+	From types.ServiceStatus `json:"from,omitempty"`
+	// To is a new status of the service.
+	To types.ServiceStatus `json:"to,omitempty"`
+	// Attempt is a number of restart attempts of the service.
+	Attempt int `json:"attempt,omitempty"`
+
+	// Error message, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// Transition converts service record to transition event, it returns
+// false for lifecycle records. Service ID is not stored in journal.
+func (r JournalRecord) Transition() (TransitionEvent, bool) {
+	if r.Kind != JournalService {
+		return TransitionEvent{}, false
+	}
+	ev := TransitionEvent{
+		Service: r.Service,
+		From:    r.From,
+		To:      r.To,
+		At:      r.Time,
+		Attempt: r.Attempt,
+	}
+	if r.Error != "" {
+		ev.Error = errors.New(r.Error)
+	}
+	return ev, true
+}
+
+// JournalConfig is a configuration of transitions journal.
+type JournalConfig struct {
+	// Path of journal file.
+	Path string
+	// MaxSize of journal file in bytes, journal is rotated when it's exceeded.
+	MaxSize int64
+	// MaxFiles is a number of rotated files kept, rotated files have
+	// numeric suffix: `journal.jsonl.1` is the newest one.
+	MaxFiles int
+}
+
+// DefaultJournalConfig is a default journal configuration without path.
+var DefaultJournalConfig = JournalConfig{
+	MaxSize:  10 << 20,
+	MaxFiles: 3,
+}
+
+func (c *JournalConfig) check() {
+	if c.MaxSize <= 0 {
+		c.MaxSize = DefaultJournalConfig.MaxSize
+	}
+	if c.MaxFiles <= 0 {
+		c.MaxFiles = DefaultJournalConfig.MaxFiles
+	}
+}
+
+// Journal appends lifecycle and service transitions to size-rotated
+// JSON Lines file. Journal could be attached to lifecycle with Config.Journal.
+type Journal struct {
+	cfg JournalConfig
+
+	mx   sync.Mutex
+	file *os.File
+	size int64
+}
+
+// OpenJournal opens or creates journal file.
+func OpenJournal(cfg JournalConfig) (*Journal, error) {
+	cfg.check()
+	j := &Journal{cfg: cfg}
+	if err := j.open(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+func (j *Journal) open() error {
+	f, err := os.OpenFile(j.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return errors.Wrap(err, "open journal")
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return errors.Wrap(err, "stat journal")
+	}
+	j.file = f
+	j.size = info.Size()
+	return nil
+}
+
+// Write appends record to journal.
+func (j *Journal) Write(rec JournalRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return errors.Wrap(err, "encode journal record")
+	}
+	line = append(line, '\n')
+
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+	if j.size > 0 && j.size+int64(len(line)) > j.cfg.MaxSize {
+		if err := j.rotate(); err != nil {
+			return err
+		}
+	}
+	n, err := j.file.Write(line)
+	j.size += int64(n)
+	if err != nil {
+		return errors.Wrap(err, "write journal")
+	}
+	return nil
+}
+
+func (j *Journal) rotate() error {
+	if err := j.file.Close(); err != nil {
+		return errors.Wrap(err, "close journal")
+	}
+	j.file = nil
+	for i := j.cfg.MaxFiles; i > 0; i-- {
+		src := j.cfg.Path
+		if i > 1 {
+			src = rotatedJournal(j.cfg.Path, i-1)
+		}
+		err := os.Rename(src, rotatedJournal(j.cfg.Path, i))
+		if err != nil && !os.IsNotExist(err) {
+			return errors.Wrap(err, "rotate journal")
+		}
+	}
+	return j.open()
+}
+
+// Close journal file.
+func (j *Journal) Close() error {
+	j.mx.Lock()
+	defer j.mx.Unlock()
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
+
+func rotatedJournal(path string, n int) string {
+	return path + "." + strconv.Itoa(n)
+}
+
+// ReadJournal loads all journal records from journal file and its
+// rotated files, oldest records first.
+func ReadJournal(path string) ([]JournalRecord, error) {
+	var files []string
+	for i := 1; ; i++ {
+		name := rotatedJournal(path, i)
+		if _, err := os.Stat(name); err != nil {
+			if os.IsNotExist(err) {
+				break
+			}
+			return nil, errors.Wrap(err, "stat journal")
+		}
+		files = append(files, name)
+	}
+	for i, k := 0, len(files)-1; i < k; i, k = i+1, k-1 {
+		files[i], files[k] = files[k], files[i]
+	}
+	files = append(files, path)
+
+	var res []JournalRecord
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, errors.Wrap(err, "open journal")
+		}
+		records, err := DecodeJournal(f)
+		f.Close()
+		if err != nil {
+			return nil, errors.Wrapf(err, "read journal %s", name)
+		}
+		res = append(res, records...)
+	}
+	return res, nil
+}
+
+// DecodeJournal decodes journal records from JSON Lines reader.
+// Truncated last line is skipped.
+func DecodeJournal(r io.Reader) ([]JournalRecord, error) {
+	var res []JournalRecord
+	// decoding error of the last line is ignored, since it could be
+	// truncated if the process crashed during the write.
+	var lastErr error
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if lastErr != nil {
+			return res, lastErr
+		}
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var rec JournalRecord
+		if err := json.Unmarshal(data, &rec); err != nil {
+			lastErr = errors.Wrapf(err, "decode line %d", line)
+			continue
+		}
+		res = append(res, rec)
+	}
+	return res, scanner.Err()
+}
+
+// recordPhase writes lifecycle phase record to journal, if configured.
+func (l *Lifecycle) recordPhase(phase Phase, stage JournalStage, reason string, err error) {
+	if l.config.Journal == nil {
+		return
+	}
+	rec := JournalRecord{
+		Time:   time.Now(),
+		Kind:   JournalLifecycle,
+		Phase:  phase,
+		Stage:  stage,
+		Reason: reason,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+	l.writeJournal(rec)
+}
+
+// recordTransition writes service transition record to journal, if configured.
+func (l *Lifecycle) recordTransition(ev TransitionEvent) {
+	if l.config.Journal == nil {
+		return
+	}
+	rec := JournalRecord{
+		Time:    ev.At,
+		Kind:    JournalService,
+		Service: ev.Service,
+		From:    ev.From,
+		To:      ev.To,
+		Attempt: ev.Attempt,
+	}
+	if ev.Error != nil {
+		rec.Error = ev.Error.Error()
+	}
+	l.writeJournal(rec)
+}
+
+func (l *Lifecycle) writeJournal(rec JournalRecord) {
+	if err := l.config.Journal.Write(rec); err != nil {
+		l.config.Logger.Log(logging.LevelError, "failed to write journal", logging.Err(err))
+	}
+}
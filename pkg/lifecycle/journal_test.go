@@ -0,0 +1,95 @@
+package lifecycle
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJournalRotate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := OpenJournal(JournalConfig{Path: path, MaxSize: 200, MaxFiles: 2})
+	require.NoError(t, err)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, j.Write(JournalRecord{
+			Time:    time.Unix(int64(i), 0),
+			Kind:    JournalService,
+			Service: "svc",
+			From:    types.ServiceStatusStarting,
+			To:      types.ServiceStatusRunning,
+			Attempt: i,
+		}))
+	}
+	require.NoError(t, j.Close())
+
+	_, err = os.Stat(path + ".3")
+	require.True(t, os.IsNotExist(err))
+	records, err := ReadJournal(path)
+	require.NoError(t, err)
+	require.NotEmpty(t, records)
+	require.Less(t, len(records), 10)
+	last := records[len(records)-1]
+	require.Equal(t, 9, last.Attempt)
+	for i := 1; i < len(records); i++ {
+		require.Equal(t, records[i-1].Attempt+1, records[i].Attempt)
+	}
+}
+
+func TestDecodeJournalTruncated(t *testing.T) {
+	data := `{"time":"2024-01-01T00:00:00Z","kind":"service","service":"svc","from":"Init","to":"Starting"}
+{"time":"2024-01-01T00:00:01Z","kind":"serv`
+	records, err := DecodeJournal(strings.NewReader(data))
+	require.NoError(t, err)
+	require.Len(t, records, 1)
+	ev, ok := records[0].Transition()
+	require.True(t, ok)
+	require.Equal(t, types.ServiceStatusInit, ev.From)
+	require.Equal(t, types.ServiceStatusStarting, ev.To)
+
+	_, err = DecodeJournal(strings.NewReader("{broken\n" + data))
+	require.Error(t, err)
+}
+
+func TestLifecycleJournal(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := OpenJournal(JournalConfig{Path: path})
+	require.NoError(t, err)
+	cfg := DefaultConfig
+	cfg.Journal = j
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("svc", func(context.Context, chan<- error) error { return nil })
+
+	events := make(chan TransitionEvent, 16)
+	sub := lf.SubscribeTransitions(events, ToStatuses(types.ServiceStatusStopped))
+	defer sub.Cancel()
+	require.NoError(t, lf.Start())
+	require.NoError(t, lf.Stop())
+	receiveEvent(t, events)
+	require.NoError(t, j.Close())
+
+	records, err := ReadJournal(path)
+	require.NoError(t, err)
+	var phases []string
+	var transitions []types.ServiceStatus
+	for _, rec := range records {
+		if ev, ok := rec.Transition(); ok {
+			require.Equal(t, "svc", ev.Service)
+			transitions = append(transitions, ev.To)
+			continue
+		}
+		phases = append(phases, string(rec.Phase)+":"+string(rec.Stage)+":"+rec.Reason)
+	}
+	require.Equal(t, []string{
+		"startup:begin:", "startup:end:", "shutdown:begin:stop", "shutdown:end:stop",
+	}, phases)
+	require.Equal(t, []types.ServiceStatus{
+		types.ServiceStatusStarting, types.ServiceStatusRunning, types.ServiceStatusReady,
+		types.ServiceStatusStopping, types.ServiceStatusStopped,
+	}, transitions)
+}
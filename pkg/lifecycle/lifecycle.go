@@ -13,11 +13,13 @@ package lifecycle
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/g4s8/go-lifecycle/internal/lifecycle"
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/tracing"
 	"github.com/g4s8/go-lifecycle/pkg/types"
 	"github.com/pkg/errors"
-	"go.uber.org/multierr"
 )
 
 // Lifecycle represents application lifecycle manager.
@@ -28,10 +30,18 @@ type Lifecycle struct {
 	mx       sync.RWMutex
 	services []*lifecycle.ServiceEntry
 	configs  []types.ServiceConfig
+	ids      map[string]int
 	stateMx  sync.RWMutex
-	states   []lifecycle.ServiceState
+	states   []ServiceState
+	changeCh chan struct{}
 	doneCh   chan struct{}
-	statePub *publisher[[]ServiceState]
+	started  bool
+	// cancelStart interrupts crash-loop backoff of current startup.
+	cancelStart context.CancelFunc
+	batch       batch
+	report      StartupReport
+	statePub    *publisher[[]ServiceState]
+	eventPub    *publisher[TransitionEvent]
 }
 
 // New creates new lifecycle manager.
@@ -39,8 +49,28 @@ func New(config Config) *Lifecycle {
 	config.check()
 	return &Lifecycle{
 		config:   config,
+		ids:      make(map[string]int),
+		changeCh: make(chan struct{}),
 		doneCh:   make(chan struct{}),
-		statePub: new(publisher[[]ServiceState]),
+		batch:    batch{doneCh: make(chan struct{})},
+		statePub: &publisher[[]ServiceState]{
+			keepLast: true,
+			onDrop:   dropLogger(config.Logger, "monitor"),
+		},
+		eventPub: &publisher[TransitionEvent]{
+			onDrop: dropLogger(config.Logger, "transitions"),
+		},
+	}
+}
+
+// dropLogger logs dropped subscription items with exponentially
+// decreasing frequency to avoid flooding the log.
+func dropLogger(logger Logger, name string) func(total uint64) {
+	return func(total uint64) {
+		if total&(total-1) == 0 {
+			logger.Log(logging.LevelWarn, "subscriber is too slow, items dropped",
+				logging.String("subscription", name), logging.Any("dropped", total))
+		}
 	}
 }
 
@@ -65,15 +95,39 @@ func (l *Lifecycle) RegisterShutdownHook(name string, h types.ShutdownHook) {
 }
 
 // RegisterService registers service to lifecycle with config.
+// Duplicate service name is logged as an error and fails Validate and Start.
 func (l *Lifecycle) RegisterService(service types.ServiceConfig) {
 	l.mx.Lock()
 	defer l.mx.Unlock()
 
+	service = l.applyMiddlewares(service)
 	stateCh := make(chan lifecycle.ServiceState)
 	go l.runServiceMonitor(len(l.services), stateCh)
-	l.services = append(l.services, lifecycle.NewServiceEntry(service, stateCh))
+	l.services = append(l.services, lifecycle.NewServiceEntry(service, stateCh,
+		l.config.Logger, l.config.Tracer))
+	if id, ok := l.ids[service.Name]; ok {
+		// Start fails on validation, the first service is used until then
+		l.config.Logger.Log(logging.LevelError, "duplicate service name",
+			logging.String("service", service.Name), logging.Int("id", id))
+	} else {
+		l.ids[service.Name] = len(l.services) - 1
+	}
+	l.stateMx.Lock()
 	l.configs = append(l.configs, service)
-	l.states = append(l.states, lifecycle.ServiceState{Status: types.ServiceStatusInit})
+	l.states = append(l.states, ServiceState{
+		ID:          len(l.states),
+		Name:        service.Name,
+		Status:      types.ServiceStatusInit,
+		Criticality: service.Criticality,
+		Since:       time.Now(),
+	})
+	l.stateMx.Unlock()
+}
+
+// Register service implemented as a type, its configuration
+// is built with types.NewServiceConfig.
+func (l *Lifecycle) Register(svc types.Service) {
+	l.RegisterService(types.NewServiceConfig(svc))
 }
 
 // Statuses returns current statuses of all registered services and hooks.
@@ -81,73 +135,411 @@ func (l *Lifecycle) Statuses() []ServiceState {
 	l.stateMx.RLock()
 	defer l.stateMx.RUnlock()
 
+	return l.snapshot()
+}
+
+// snapshot of all services states, state lock should be held by caller.
+func (l *Lifecycle) snapshot() []ServiceState {
 	states := make([]ServiceState, len(l.states))
-	for i, state := range l.states {
-		states[i] = ServiceState{
-			ID:     i,
-			Name:   l.configs[i].Name,
-			Status: state.Status,
-			Error:  state.Error,
-		}
+	copy(states, l.states)
+	for i := range states {
+		states[i].Errors = append([]ErrorRecord(nil), states[i].Errors...)
 	}
 	return states
 }
 
-// SubscribeMonitor subscribes to lifecycle service state monitor.
+// SubscribeMonitor subscribes to lifecycle service state monitor with default
+// subscription config. Subscriber receives snapshot of all services states on each transition.
 func (l *Lifecycle) SubscribeMonitor(ch chan<- []ServiceState) Subscription {
-	return l.statePub.subscribe(ch)
+	return l.SubscribeMonitorWith(ch, DefaultSubscriptionConfig)
+}
+
+// SubscribeMonitorWith subscribes to lifecycle service state monitor with subscription config.
+func (l *Lifecycle) SubscribeMonitorWith(ch chan<- []ServiceState, cfg SubscriptionConfig) Subscription {
+	return l.statePub.subscribe(ch, cfg, nil)
+}
+
+// SubscribeTransitions subscribes to service transition events with default subscription config.
+// Subscriber receives only events matching all filters, if any.
+func (l *Lifecycle) SubscribeTransitions(ch chan<- TransitionEvent, filters ...TransitionFilter) Subscription {
+	return l.SubscribeTransitionsWith(ch, DefaultSubscriptionConfig, filters...)
+}
+
+// SubscribeTransitionsWith subscribes to service transition events with subscription config.
+func (l *Lifecycle) SubscribeTransitionsWith(ch chan<- TransitionEvent, cfg SubscriptionConfig,
+	filters ...TransitionFilter,
+) Subscription {
+	return l.eventPub.subscribe(ch, cfg, matchAll(filters))
 }
 
 // Start starts all registered startup hooks.
+// Crash-loop backoff before startup is interrupted by Stop or Close.
 func (l *Lifecycle) Start() error {
+	guard, delay, skip, err := l.startupBackoff()
+	if err != nil {
+		return err
+	}
+
 	l.mx.RLock()
 	defer l.mx.RUnlock()
 
-	baseCtx := context.Background()
+	l.recordPhase(PhaseStartup, JournalBegin, "", nil)
+	report := StartupReport{
+		Time:     time.Now(),
+		Services: make([]ServiceStartup, len(l.services)),
+	}
+	for i, cfg := range l.configs {
+		report.Services[i] = ServiceStartup{
+			Name:      cfg.Name,
+			DependsOn: cfg.DependsOn,
+			Result:    StartupNotStarted,
+		}
+	}
+	baseCtx, span := l.config.Tracer.Start(context.Background(), "lifecycle.startup",
+		tracing.Attr("services", len(l.services)))
+	if delay > 0 {
+		span.SetAttributes(tracing.Attr("crash_loop_delay", delay.String()))
+	}
+
 	startCtx, cancel := context.WithTimeout(baseCtx, l.config.StartupTimeout)
 	defer cancel()
 
-	var errs error
-	for _, svc := range l.services {
+	results := make([]ServiceResult, len(l.services))
+	for i, cfg := range l.configs {
+		results[i] = ServiceResult{ID: i, Service: cfg.Name, Outcome: OutcomeNotStarted}
+	}
+	var failed bool
+startup:
+	for i, svc := range l.services {
 		select {
 		case <-startCtx.Done():
-			errs = multierr.Append(errs, errors.Wrap(startCtx.Err(), "startup timeout"))
-			break
+			break startup
 		default:
 		}
+		if skip[i] {
+			report.Services[i].Result = StartupSkipped
+			results[i].Outcome = OutcomeSkipped
+			continue
+		}
 
-		if err := svc.Start(startCtx); err != nil {
-			errs = multierr.Append(errs, err)
+		name := l.configs[i].Name
+		var err error
+		if l.configs[i].WaitDependencies {
+			err = l.waitDependencies(startCtx, l.configs[i].DependsOn)
+		}
+		// dependencies wait is a part of service wait time, not its startup duration
+		begin := time.Now()
+		if err == nil {
+			guard.begin(name, begin)
+			err = svc.Start(startCtx)
+			guard.end(name, err)
+		}
+		report.Services[i].Wait = begin.Sub(report.Time)
+		report.Services[i].Duration = time.Since(begin)
+		report.Services[i].Result = StartupOK
+		results[i].Outcome = OutcomeStarted
+		if err != nil {
+			report.Services[i].Result = StartupFailed
+			report.Services[i].Error = err
+			results[i].Outcome = OutcomeFailed
+			results[i].Error = err
+			failed = true
 			if l.config.StartStrategy.checkFlag(StartStrategyFailFast) {
 				break
 			}
 		}
 	}
+	if l.config.WaitReady && !failed {
+		var started []int
+		for i := range results {
+			if results[i].Outcome == OutcomeStarted {
+				started = append(started, i)
+			}
+		}
+		if id, err := l.waitReady(startCtx, started); err != nil {
+			results[id].Outcome = OutcomeFailed
+			results[id].Error = err
+			failed = true
+		}
+	}
+	timeout := errors.Is(startCtx.Err(), context.DeadlineExceeded)
 
-	if errs != nil {
+	var errs error
+	if failed || timeout {
 		if l.config.StartStrategy.checkFlag(StartStrategyRollbackOnError) {
 			stopCtx, cancel := context.WithTimeout(baseCtx, l.config.ShutdownTimeout)
 			defer cancel()
 
-			if err := l.stop(stopCtx, true); err != nil {
-				errs = multierr.Append(errs, errors.Wrap(err, "failed to stop lifecycle"))
+			stopped, _ := l.stop(stopCtx, PhaseRollback, "startup failed")
+			for _, r := range stopped {
+				if results[r.ID].Outcome != OutcomeStarted {
+					continue
+				}
+				results[r.ID].Outcome = OutcomeRolledBack
+				if r.Error != nil {
+					results[r.ID].Outcome = OutcomeRollbackFailed
+					results[r.ID].Error = r.Error
+				}
 			}
 		}
-		return errs
+		errs = &StartError{Services: results, Timeout: timeout}
 	}
 
+	report.Duration = time.Since(report.Time)
+	report.Error = errs
+	report.CriticalPath, report.CriticalPathDuration = criticalPath(report.Services)
+	l.stateMx.Lock()
+	l.report = report
+	l.stateMx.Unlock()
+	l.logStartupReport(report)
+
+	l.recordPhase(PhaseStartup, JournalEnd, "", errs)
+	span.End(errs)
+	if errs == nil {
+		l.stateMx.Lock()
+		l.started = true
+		l.stateMx.Unlock()
+		l.checkBatch()
+	}
+	return errs
+}
+
+// startupBackoff validates services and waits for crash-loop backoff delay
+// without holding the lock, it returns ErrStartupInterrupted if lifecycle
+// was stopped or closed during the backoff.
+func (l *Lifecycle) startupBackoff() (*crashLoopGuard, time.Duration, map[int]bool, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	l.stateMx.Lock()
+	l.cancelStart = cancel
+	l.stateMx.Unlock()
+	defer func() {
+		l.stateMx.Lock()
+		l.cancelStart = nil
+		l.stateMx.Unlock()
+	}()
+
+	l.mx.RLock()
+	if err := l.validate(); err != nil {
+		l.mx.RUnlock()
+		return nil, 0, nil, err
+	}
+	guard := loadCrashLoopGuard(l.config.CrashLoop, l.config.Logger)
+	delay, skip := guard.plan(l.configs, time.Now())
+	l.mx.RUnlock()
+	if delay <= 0 {
+		return guard, delay, skip, nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return guard, delay, skip, nil
+	case <-ctx.Done():
+		return nil, 0, nil, ErrStartupInterrupted
+	case <-l.doneCh:
+		return nil, 0, nil, ErrStartupInterrupted
+	}
+}
+
+// interruptStart interrupts crash-loop backoff of startup in progress, if any.
+func (l *Lifecycle) interruptStart() {
+	l.stateMx.RLock()
+	cancel := l.cancelStart
+	l.stateMx.RUnlock()
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// waitDependencies waits until services with names are ready.
+func (l *Lifecycle) waitDependencies(ctx context.Context, names []string) error {
+	ids := make([]int, 0, len(names))
+	for _, name := range names {
+		id, ok := l.ids[name]
+		if !ok {
+			return errors.Wrapf(ErrServiceNotFound, "dependency %q", name)
+		}
+		ids = append(ids, id)
+	}
+	if id, err := l.waitReady(ctx, ids); err != nil {
+		return errors.Wrapf(err, "dependency %q", l.configs[id].Name)
+	}
 	return nil
 }
 
+// waitReady waits until services with ids are ready, degraded or completed, it returns
+// ID and error of the first service which was stopped, paused, failed without restart
+// or was not ready before context is done.
+func (l *Lifecycle) waitReady(ctx context.Context, ids []int) (int, error) {
+	for {
+		l.stateMx.RLock()
+		pending := -1
+		var failure error
+		for _, id := range ids {
+			st := l.states[id]
+			switch st.Status {
+			case types.ServiceStatusReady, types.ServiceStatusDegraded, types.ServiceStatusCompleted:
+				// degraded service is still serving, same as for health checks
+				continue
+			}
+			switch {
+			case st.Status == types.ServiceStatusError && st.Final:
+				failure = errors.Wrap(st.Error, "service failed before ready")
+			case st.Status == types.ServiceStatusStopped:
+				failure = errors.Wrap(ErrNotReady, "service stopped")
+			case st.Status == types.ServiceStatusPaused:
+				failure = errors.Wrap(ErrNotReady, "service paused")
+			}
+			pending = id
+			break
+		}
+		changeCh := l.changeCh
+		l.stateMx.RUnlock()
+		if pending < 0 {
+			return -1, nil
+		}
+		if failure != nil {
+			return pending, failure
+		}
+		select {
+		case <-changeCh:
+		case <-ctx.Done():
+			return pending, ErrNotReady
+		}
+	}
+}
+
+// StartupReport returns timing report of the last startup,
+// it's empty if lifecycle was not started yet.
+func (l *Lifecycle) StartupReport() StartupReport {
+	l.stateMx.RLock()
+	defer l.stateMx.RUnlock()
+
+	report := l.report
+	report.Services = append([]ServiceStartup(nil), l.report.Services...)
+	report.CriticalPath = append([]string(nil), l.report.CriticalPath...)
+	return report
+}
+
 // Stop stops all registered shutdown hooks.
 func (l *Lifecycle) Stop() error {
+	l.interruptStart()
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
+	defer cancel()
+	_, err := l.stop(ctx, PhaseShutdown, "stop")
+	return err
+}
+
+// ErrServiceNotFound is returned when service with requested name is not registered.
+var ErrServiceNotFound = errors.New("service not found")
+
+// StopService stops single service by name.
+func (l *Lifecycle) StopService(name string) error {
+	svc, err := l.lookup(name)
+	if err != nil {
+		return err
+	}
 	ctx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
 	defer cancel()
-	return l.stop(ctx, false)
+	return svc.Stop(ctx)
+}
+
+// RestartService stops single service by name if it's running and starts it again.
+func (l *Lifecycle) RestartService(name string) error {
+	svc, err := l.lookup(name)
+	if err != nil {
+		return err
+	}
+	switch svc.State().Status {
+	case types.ServiceStatusStarting, types.ServiceStatusRunning, types.ServiceStatusReady,
+		types.ServiceStatusDegraded, types.ServiceStatusPaused:
+		stopCtx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
+		defer cancel()
+		if err := svc.Stop(stopCtx); err != nil {
+			return errors.Wrap(err, "stop service")
+		}
+	}
+	startCtx, cancel := context.WithTimeout(context.Background(), l.config.StartupTimeout)
+	defer cancel()
+	return svc.Start(startCtx)
+}
+
+// ErrNotPausable is returned when service without pause and resume hooks is paused.
+var ErrNotPausable = errors.New("service is not pausable")
+
+// Pause suspends running service by name with its pause hook, paused
+// service is not stopped and could be resumed with Resume.
+func (l *Lifecycle) Pause(name string) error {
+	svc, err := l.pausable(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.ShutdownTimeout)
+	defer cancel()
+	return svc.Pause(ctx)
+}
+
+// Resume paused service by name with its resume hook.
+func (l *Lifecycle) Resume(name string) error {
+	svc, err := l.pausable(name)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.StartupTimeout)
+	defer cancel()
+	return svc.Resume(ctx)
+}
+
+// ErrNotReloadable is returned when service without reload hook is reloaded.
+var ErrNotReloadable = errors.New("service is not reloadable")
+
+// Reload running service by name with its reload hook, failed
+// reload makes the service degraded until the next successful reload.
+func (l *Lifecycle) Reload(name string) error {
+	svc, err := l.lookup(name)
+	if err != nil {
+		return err
+	}
+	l.mx.RLock()
+	reloadable := l.configs[l.ids[name]].ReloadHook != nil
+	l.mx.RUnlock()
+	if !reloadable {
+		return errors.Wrapf(ErrNotReloadable, "service %q", name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), l.config.StartupTimeout)
+	defer cancel()
+	return svc.Reload(ctx)
+}
+
+func (l *Lifecycle) pausable(name string) (*lifecycle.ServiceEntry, error) {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	id, ok := l.ids[name]
+	if !ok {
+		return nil, errors.Wrapf(ErrServiceNotFound, "service %q", name)
+	}
+	if cfg := l.configs[id]; cfg.PauseHook == nil || cfg.ResumeHook == nil {
+		return nil, errors.Wrapf(ErrNotPausable, "service %q", name)
+	}
+	return l.services[id], nil
+}
+
+func (l *Lifecycle) lookup(name string) (*lifecycle.ServiceEntry, error) {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+
+	if id, ok := l.ids[name]; ok {
+		return l.services[id], nil
+	}
+	return nil, errors.Wrapf(ErrServiceNotFound, "service %q", name)
 }
 
 // Close closes lifecycle manager.
 func (l *Lifecycle) Close() error {
+	l.interruptStart()
 	for _, svc := range l.services {
 		svc.Close()
 	}
@@ -155,21 +547,105 @@ func (l *Lifecycle) Close() error {
 	return nil
 }
 
-func (l *Lifecycle) stop(ctx context.Context, rollback bool) error {
+// stop all services in reverse order, on rollback phase
+// only started services are stopped. Services failed without restart
+// are skipped to keep their error. It returns outcomes of stopped
+// services and StopError if any service failed to stop.
+func (l *Lifecycle) stop(ctx context.Context, phase Phase, reason string) ([]ServiceResult, error) {
 	l.mx.RLock()
 	defer l.mx.RUnlock()
 
-	var errs error
+	l.recordPhase(phase, JournalBegin, reason, nil)
+	ctx, span := l.config.Tracer.Start(ctx, "lifecycle."+string(phase), tracing.Attr("reason", reason))
+	var results []ServiceResult
+	var failed bool
 	for i := len(l.services) - 1; i >= 0; i-- {
 		svc := l.services[i]
-		if rollback && svc.State().Status == types.ServiceStatusInit {
+		if phase == PhaseRollback && svc.State().Status == types.ServiceStatusInit {
+			continue
+		}
+		if st := svc.State(); st.Status == types.ServiceStatusError && st.Final {
 			continue
 		}
+		res := ServiceResult{ID: i, Service: l.configs[i].Name, Outcome: OutcomeStopped}
 		if err := svc.Stop(ctx); err != nil {
-			errs = multierr.Append(errs, err)
+			res.Outcome = OutcomeStopFailed
+			res.Error = err
+			failed = true
 		}
+		results = append(results, res)
+	}
+	var errs error
+	if failed {
+		errs = &StopError{
+			Services: results,
+			Timeout:  errors.Is(ctx.Err(), context.DeadlineExceeded),
+		}
+	}
+	l.recordPhase(phase, JournalEnd, reason, errs)
+	span.End(errs)
+	return results, errs
+}
+
+// updateState of the service with new state from service entry,
+// state lock should be held by caller. It returns transition event
+// if the status of service was changed.
+func (l *Lifecycle) updateState(id int, state lifecycle.ServiceState, now time.Time) (ev TransitionEvent, changed bool) {
+	st := &l.states[id]
+	if state.Report != nil {
+		l.applyReport(st, state.Report)
+		return
+	}
+	if changed = st.Status != state.Status; changed {
+		ev = TransitionEvent{
+			Service: st.Name,
+			ID:      id,
+			From:    st.Status,
+			To:      state.Status,
+			Error:   state.Error,
+			At:      now,
+			Attempt: state.Attempt,
+		}
+		switch {
+		case st.Status == types.ServiceStatusStarting && state.Status == types.ServiceStatusRunning:
+			st.StartDuration = now.Sub(st.Since)
+		case st.Status == types.ServiceStatusStopping && state.Status == types.ServiceStatusStopped:
+			st.StopDuration = now.Sub(st.Since)
+		}
+		if state.Status == types.ServiceStatusError && state.Error != nil {
+			l.appendError(st, state.Error, now)
+		}
+		st.Since = now
+	}
+	st.DegradedReason = ""
+	if state.Status == types.ServiceStatusDegraded {
+		st.DegradedReason = state.Reason
+	}
+	st.Status = state.Status
+	st.Error = state.Error
+	st.Final = state.Final
+	st.Restarts = state.Attempt
+	st.NextRestart = state.NextRestart
+	return
+}
+
+// applyReport of the service runtime state.
+func (l *Lifecycle) applyReport(st *ServiceState, r *lifecycle.Report) {
+	switch r.Kind {
+	case lifecycle.ReportIgnored:
+		st.IgnoredErrors++
+		l.appendError(st, r.Error, r.Time)
+	case lifecycle.ReportHeartbeat:
+		st.LastHeartbeat = r.Time
+	}
+}
+
+// appendError to service errors history.
+func (l *Lifecycle) appendError(st *ServiceState, err error, now time.Time) {
+	st.Errors = append(st.Errors, ErrorRecord{Time: now, Error: err})
+	if n := len(st.Errors) - l.config.ErrorsHistorySize; n > 0 {
+		st.Errors = st.Errors[n:]
 	}
-	return errs
 }
 
 func (l *Lifecycle) runServiceMonitor(id int, stateCh chan lifecycle.ServiceState) {
@@ -177,21 +653,43 @@ func (l *Lifecycle) runServiceMonitor(id int, stateCh chan lifecycle.ServiceStat
 		select {
 		case state := <-stateCh:
 			l.stateMx.Lock()
-			l.states[id] = state
-			l.stateMx.Unlock()
-			newState := make([]ServiceState, len(l.states))
-			for i, state := range l.states {
-				newState[i] = ServiceState{
-					ID:     i,
-					Name:   l.configs[i].Name,
-					Status: state.Status,
-					Error:  state.Error,
-				}
+			ev, changed := l.updateState(id, state, time.Now())
+			if changed {
+				close(l.changeCh)
+				l.changeCh = make(chan struct{})
 			}
+			newState := l.snapshot()
+			l.stateMx.Unlock()
 			l.statePub.publish(newState)
+			if changed {
+				l.logTransition(ev)
+				l.recordTransition(ev)
+				l.eventPub.publish(ev)
+			}
+			l.checkBatch()
 		case <-l.doneCh:
 			close(stateCh)
 			return
 		}
 	}
 }
+
+// logTransition logs service transition, transitions to error status
+// are logged with error level.
+func (l *Lifecycle) logTransition(ev TransitionEvent) {
+	level := logging.LevelInfo
+	fields := []logging.Field{
+		logging.String("service", ev.Service),
+		logging.Int("id", ev.ID),
+		logging.Any("from", ev.From),
+		logging.Any("to", ev.To),
+	}
+	if ev.Attempt > 0 {
+		fields = append(fields, logging.Int("attempt", ev.Attempt))
+	}
+	if ev.Error != nil {
+		level = logging.LevelError
+		fields = append(fields, logging.Err(ev.Error))
+	}
+	l.config.Logger.Log(level, "service transition", fields...)
+}
@@ -0,0 +1,395 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/tracing"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLifecycle(t *testing.T, cfg Config) *Lifecycle {
+	t.Helper()
+	lf := New(cfg)
+	t.Cleanup(func() {
+		lf.Close()
+	})
+	return lf
+}
+
+func TestStatusesSnapshot(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	targetErr := errors.New("runtime error")
+	var failed bool
+	lf.RegisterService(types.ServiceConfig{
+		Name: "svc",
+		StartupHook: func(_ context.Context, errCh chan<- error) error {
+			time.Sleep(time.Millisecond * 5)
+			if !failed {
+				failed = true
+				go func() { errCh <- targetErr }()
+			}
+			return nil
+		},
+		RestartPolicy: types.ServiceRestartPolicy{RestartOnFailure: true},
+	})
+	require.NoError(t, lf.Start())
+	require.Eventually(t, func() bool {
+		st := lf.Statuses()[0]
+		return st.Status == types.ServiceStatusReady && st.Restarts == 1
+	}, time.Second, time.Millisecond)
+
+	st := lf.Statuses()[0]
+	require.Equal(t, "svc", st.Name)
+	require.GreaterOrEqual(t, st.StartDuration, time.Millisecond*5)
+	require.False(t, st.Since.IsZero())
+	require.Len(t, st.Errors, 1)
+	require.ErrorIs(t, st.Errors[0].Error, targetErr)
+
+	require.NoError(t, lf.Stop())
+	require.Eventually(t, func() bool {
+		return lf.Statuses()[0].Status == types.ServiceStatusStopped
+	}, time.Second, time.Millisecond)
+}
+
+func TestSubscribeTransitions(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	lf.RegisterStartupHook("first", func(context.Context, chan<- error) error { return nil })
+	lf.RegisterStartupHook("second", func(context.Context, chan<- error) error { return nil })
+
+	all := make(chan TransitionEvent, 16)
+	allSub := lf.SubscribeTransitions(all)
+	defer allSub.Cancel()
+	filtered := make(chan TransitionEvent, 16)
+	filteredSub := lf.SubscribeTransitions(filtered,
+		ForServices("second"), ToStatuses(types.ServiceStatusRunning))
+	defer filteredSub.Cancel()
+
+	require.NoError(t, lf.Start())
+
+	ev := receiveEvent(t, filtered)
+	require.Equal(t, "second", ev.Service)
+	require.Equal(t, 1, ev.ID)
+	require.Equal(t, types.ServiceStatusStarting, ev.From)
+	require.Equal(t, types.ServiceStatusRunning, ev.To)
+	require.False(t, ev.At.IsZero())
+
+	services := make([]string, 0, 6)
+	for i := 0; i < 6; i++ {
+		services = append(services, receiveEvent(t, all).Service)
+	}
+	require.ElementsMatch(t, []string{"first", "first", "first", "second", "second", "second"}, services)
+	select {
+	case ev := <-filtered:
+		t.Fatalf("unexpected event: %+v", ev)
+	default:
+	}
+}
+
+func receiveEvent(t *testing.T, ch <-chan TransitionEvent) TransitionEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("transition event timeout")
+	}
+	return TransitionEvent{}
+}
+
+func TestTracer(t *testing.T) {
+	tracer := tracing.NewChromeTracer()
+	cfg := DefaultConfig
+	cfg.Tracer = tracer
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterService(types.ServiceConfig{
+		Name:         "svc",
+		StartupHook:  func(context.Context, chan<- error) error { return nil },
+		ShutdownHook: func(context.Context) error { return nil },
+	})
+	require.NoError(t, lf.Start())
+	require.NoError(t, lf.Stop())
+
+	var buf bytes.Buffer
+	_, err := tracer.WriteTo(&buf)
+	require.NoError(t, err)
+	var trace struct {
+		TraceEvents []struct {
+			Name  string                 `json:"name"`
+			Phase string                 `json:"ph"`
+			Args  map[string]interface{} `json:"args"`
+		} `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+	var names []string
+	for _, ev := range trace.TraceEvents {
+		if ev.Phase != "X" {
+			continue
+		}
+		names = append(names, ev.Name)
+		if strings.HasPrefix(ev.Name, "service.") {
+			require.Equal(t, "svc", ev.Args["service"])
+			require.Equal(t, "ok", ev.Args["outcome"])
+		}
+	}
+	require.Equal(t, []string{"service.start", "lifecycle.startup", "service.stop", "lifecycle.shutdown"}, names)
+}
+
+func TestIgnoredErrors(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	targetErr := errors.New("ignorable error")
+	lf.RegisterStartupHook("svc", func(_ context.Context, errCh chan<- error) error {
+		go func() {
+			errCh <- types.Ignorable(targetErr)
+			errCh <- types.Ignorable(targetErr)
+		}()
+		return nil
+	})
+	require.NoError(t, lf.Start())
+	require.Eventually(t, func() bool {
+		return lf.Statuses()[0].IgnoredErrors == 2
+	}, time.Second, time.Millisecond)
+	st := lf.Statuses()[0]
+	require.Equal(t, types.ServiceStatusReady, st.Status)
+	require.Equal(t, 0, st.Restarts)
+	require.Len(t, st.Errors, 2)
+	require.ErrorIs(t, st.Errors[0].Error, targetErr)
+}
+
+func TestReporter(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	reported := make(chan types.Reporter, 1)
+	lf.RegisterStartupHook("svc", types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+		reported <- r
+		return nil
+	}))
+	require.NoError(t, lf.Start())
+	r := <-reported
+
+	r.Heartbeat()
+	r.Degraded("consumer lag")
+	require.Eventually(t, func() bool {
+		st := lf.Statuses()[0]
+		return !st.LastHeartbeat.IsZero() && st.Status == types.ServiceStatusDegraded &&
+			st.DegradedReason == "consumer lag"
+	}, time.Second, time.Millisecond)
+
+	r.Recovered()
+	require.Eventually(t, func() bool {
+		st := lf.Statuses()[0]
+		return st.Status == types.ServiceStatusReady && st.DegradedReason == ""
+	}, time.Second, time.Millisecond)
+}
+
+func TestPauseResume(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	var paused int32
+	lf.RegisterService(types.ServiceConfig{
+		Name:        "consumer",
+		StartupHook: func(context.Context, chan<- error) error { return nil },
+		PauseHook: func(context.Context) error {
+			atomic.StoreInt32(&paused, 1)
+			return nil
+		},
+		ResumeHook: func(context.Context) error {
+			atomic.StoreInt32(&paused, 0)
+			return nil
+		},
+	})
+	lf.RegisterStartupHook("web", func(context.Context, chan<- error) error { return nil })
+
+	require.Error(t, lf.Pause("consumer"), "service is not started")
+	require.NoError(t, lf.Start())
+	require.ErrorIs(t, lf.Pause("web"), ErrNotPausable)
+	require.ErrorIs(t, lf.Pause("db"), ErrServiceNotFound)
+	require.Error(t, lf.Resume("consumer"), "running service can't be resumed")
+
+	require.NoError(t, lf.Pause("consumer"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&paused))
+	require.Eventually(t, func() bool {
+		return lf.Statuses()[0].Status == types.ServiceStatusPaused
+	}, time.Second, time.Millisecond)
+
+	require.NoError(t, lf.Resume("consumer"))
+	require.Equal(t, int32(0), atomic.LoadInt32(&paused))
+	require.Eventually(t, func() bool {
+		return lf.Statuses()[0].Status == types.ServiceStatusReady
+	}, time.Second, time.Millisecond)
+	require.NoError(t, lf.Stop())
+}
+
+func TestWaitReady(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.WaitReady = true
+	lf := newTestLifecycle(t, cfg)
+	var consumerReady int32
+	lf.RegisterService(types.ServiceConfig{
+		Name:            "consumer",
+		ReadinessSignal: true,
+		StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				atomic.StoreInt32(&consumerReady, 1)
+				r.Ready()
+			}()
+			return nil
+		}),
+	})
+	var webStarted int32
+	lf.RegisterService(types.ServiceConfig{
+		Name:             "web",
+		DependsOn:        []string{"consumer"},
+		WaitDependencies: true,
+		StartupHook: func(context.Context, chan<- error) error {
+			webStarted = atomic.LoadInt32(&consumerReady)
+			return nil
+		},
+	})
+	require.NoError(t, lf.Start())
+	require.Equal(t, int32(1), webStarted, "dependent service started before dependency is ready")
+	for _, st := range lf.Statuses() {
+		require.Equal(t, types.ServiceStatusReady, st.Status)
+	}
+	web := lf.StartupReport().Services[1]
+	require.GreaterOrEqual(t, web.Wait, 10*time.Millisecond, "dependency wait is a part of wait time")
+	require.Less(t, web.Duration, 10*time.Millisecond, "dependency wait is not a part of startup duration")
+}
+
+func TestWaitReadyTimeout(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.WaitReady = true
+	cfg.StartupTimeout = 20 * time.Millisecond
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterService(types.ServiceConfig{
+		Name:            "consumer",
+		ReadinessSignal: true,
+		StartupHook:     func(context.Context, chan<- error) error { return nil },
+	})
+	err := lf.Start()
+	require.ErrorIs(t, err, ErrNotReady)
+	require.ErrorIs(t, err, ErrStartupTimeout)
+}
+
+type testService struct {
+	started  int32
+	stopped  int32
+	paused   int32
+	reloaded int32
+}
+
+func (s *testService) Start(_ context.Context, r types.Reporter) error {
+	atomic.StoreInt32(&s.started, 1)
+	r.Heartbeat()
+	return nil
+}
+
+func (s *testService) Stop(context.Context) error {
+	atomic.StoreInt32(&s.stopped, 1)
+	return nil
+}
+
+func (s *testService) Pause(context.Context) error {
+	atomic.StoreInt32(&s.paused, 1)
+	return nil
+}
+
+func (s *testService) Resume(context.Context) error {
+	atomic.StoreInt32(&s.paused, 0)
+	return nil
+}
+
+func (s *testService) Reload(context.Context) error {
+	atomic.AddInt32(&s.reloaded, 1)
+	return nil
+}
+
+func (*testService) Name() string { return "consumer" }
+
+func (*testService) Criticality() types.ServiceCriticality { return types.ServiceOptional }
+
+type testDependentService struct{}
+
+func (testDependentService) Start(context.Context, types.Reporter) error { return nil }
+
+func (testDependentService) Stop(context.Context) error { return nil }
+
+func (testDependentService) DependsOn() []string { return []string{"consumer"} }
+
+func TestRegister(t *testing.T) {
+	lf := newTestLifecycle(t, DefaultConfig)
+	svc := &testService{}
+	lf.Register(svc)
+	lf.Register(testDependentService{})
+	require.NoError(t, lf.Start())
+	require.Equal(t, int32(1), atomic.LoadInt32(&svc.started))
+
+	st := lf.Statuses()
+	require.Equal(t, "consumer", st[0].Name)
+	require.Equal(t, types.ServiceOptional, st[0].Criticality)
+	require.Equal(t, "lifecycle.testDependentService", st[1].Name)
+
+	require.NoError(t, lf.Pause("consumer"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&svc.paused))
+	require.NoError(t, lf.Resume("consumer"))
+	require.Eventually(t, func() bool {
+		return lf.Statuses()[0].Status == types.ServiceStatusReady
+	}, time.Second, time.Millisecond)
+	require.NoError(t, lf.Reload("consumer"))
+	require.Equal(t, int32(1), atomic.LoadInt32(&svc.reloaded))
+	require.ErrorIs(t, lf.Reload("lifecycle.testDependentService"), ErrNotReloadable)
+	require.NoError(t, lf.Stop())
+	require.Equal(t, int32(1), atomic.LoadInt32(&svc.stopped))
+}
+
+func TestWaitReadyDegraded(t *testing.T) {
+	cfg := DefaultConfig
+	cfg.WaitReady = true
+	cfg.StartupTimeout = time.Second
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterService(types.ServiceConfig{
+		Name:            "consumer",
+		ReadinessSignal: true,
+		StartupHook: types.ReporterHook(func(_ context.Context, r types.Reporter) error {
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				r.Degraded("consumer lag")
+			}()
+			return nil
+		}),
+	})
+	lf.RegisterService(types.ServiceConfig{
+		Name:             "web",
+		DependsOn:        []string{"consumer"},
+		WaitDependencies: true,
+		StartupHook:      func(context.Context, chan<- error) error { return nil },
+	})
+	start := time.Now()
+	require.NoError(t, lf.Start())
+	require.Less(t, time.Since(start), cfg.StartupTimeout/2, "degraded dependency should not block startup")
+	require.Equal(t, types.ServiceStatusDegraded, lf.Statuses()[0].Status)
+}
+
+func TestCloseReleasesGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for i := 0; i < 50; i++ {
+		lf := New(DefaultConfig)
+		lf.RegisterStartupHook("first", func(context.Context, chan<- error) error { return nil })
+		lf.RegisterStartupHook("second", func(context.Context, chan<- error) error { return nil })
+		require.NoError(t, lf.Start())
+		require.NoError(t, lf.Stop())
+		require.NoError(t, lf.Close())
+	}
+	// require.Eventually runs condition in a new goroutine, so goroutines are polled in the test
+	for i := 0; i < 100 && runtime.NumGoroutine() > before; i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), before, "goroutines leaked")
+}
@@ -3,17 +3,16 @@ package lifecycle
 import (
 	"fmt"
 	"io"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 )
 
-// Logger for lifecycle messages.
-type Logger interface {
-	// Printf prints formatted message.
-	Printf(format string, v ...interface{})
-}
+// Logger for lifecycle messages, see logging.Logger.
+type Logger = logging.Logger
 
 type nopLogger struct{}
 
-func (nopLogger) Printf(format string, v ...interface{}) {}
+func (nopLogger) Log(logging.Level, string, ...logging.Field) {}
 
 // NopLogger is a no-op logger.
 var NopLogger = nopLogger{}
@@ -33,3 +32,8 @@ func NewStdLogger(out io.Writer) *StdLogger {
 func (l *StdLogger) Printf(format string, v ...interface{}) {
 	fmt.Fprintf(l.out, format, v...)
 }
+
+// Log writes message formatted with logging.Format as a single line.
+func (l *StdLogger) Log(level logging.Level, msg string, fields ...logging.Field) {
+	fmt.Fprintln(l.out, logging.Format(level, msg, fields...))
+}
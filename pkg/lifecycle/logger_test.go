@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,3 +14,10 @@ func TestStdLogger(t *testing.T) {
 	l.Printf("hello %s", "world")
 	require.Equal(t, "hello world", buf.String())
 }
+
+func TestStdLoggerLog(t *testing.T) {
+	var buf bytes.Buffer
+	l := NewStdLogger(&buf)
+	l.Log(logging.LevelInfo, "started", logging.String("service", "web"))
+	require.Equal(t, "INFO started service=web\n", buf.String())
+}
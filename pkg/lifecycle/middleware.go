@@ -0,0 +1,105 @@
+package lifecycle
+
+import (
+	"context"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// applyMiddlewares wraps service hooks with lifecycle and service middlewares.
+// Service name is added to hook context by the outermost wrapper.
+func (l *Lifecycle) applyMiddlewares(service types.ServiceConfig) types.ServiceConfig {
+	middlewares := make([]types.Middleware, 0, len(l.config.Middlewares)+len(service.Middlewares))
+	middlewares = append(middlewares, l.config.Middlewares...)
+	middlewares = append(middlewares, service.Middlewares...)
+	name := service.Name
+	if start := service.StartupHook; start != nil {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if m := middlewares[i].Startup; m != nil {
+				start = m(start)
+			}
+		}
+		service.StartupHook = func(ctx context.Context, errCh chan<- error) error {
+			return start(types.WithServiceName(ctx, name), errCh)
+		}
+	}
+	if stop := service.ShutdownHook; stop != nil {
+		for i := len(middlewares) - 1; i >= 0; i-- {
+			if m := middlewares[i].Shutdown; m != nil {
+				stop = m(stop)
+			}
+		}
+		service.ShutdownHook = func(ctx context.Context) error {
+			return stop(types.WithServiceName(ctx, name))
+		}
+	}
+	return service
+}
+
+// TimingMiddleware calls observer with duration and result of each hook invocation,
+// e.g. to record hook metrics. Phase is either PhaseStartup or PhaseShutdown.
+func TimingMiddleware(observer func(service string, phase Phase, d time.Duration, err error)) types.Middleware {
+	return types.Middleware{
+		Startup: func(next types.StartupHook) types.StartupHook {
+			return func(ctx context.Context, errCh chan<- error) error {
+				start := time.Now()
+				err := next(ctx, errCh)
+				observer(types.ServiceName(ctx), PhaseStartup, time.Since(start), err)
+				return err
+			}
+		},
+		Shutdown: func(next types.ShutdownHook) types.ShutdownHook {
+			return func(ctx context.Context) error {
+				start := time.Now()
+				err := next(ctx)
+				observer(types.ServiceName(ctx), PhaseShutdown, time.Since(start), err)
+				return err
+			}
+		},
+	}
+}
+
+// LoggingMiddleware logs each hook invocation and its result with duration.
+func LoggingMiddleware(logger Logger) types.Middleware {
+	return TimingMiddleware(func(service string, phase Phase, d time.Duration, err error) {
+		fields := []logging.Field{
+			logging.String("service", service),
+			logging.String("phase", string(phase)),
+			logging.Any("duration", d),
+		}
+		if err != nil {
+			logger.Log(logging.LevelError, "service hook failed", append(fields, logging.Err(err))...)
+			return
+		}
+		logger.Log(logging.LevelInfo, "service hook completed", fields...)
+	})
+}
+
+// RecoveryMiddleware recovers panics in hooks and returns them as hook errors
+// wrapping types.PanicError. Lifecycle recovers hooks panics anyway, this middleware
+// allows outer middlewares to observe panics as errors.
+func RecoveryMiddleware() types.Middleware {
+	return types.Middleware{
+		Startup: func(next types.StartupHook) types.StartupHook {
+			return func(ctx context.Context, errCh chan<- error) (err error) {
+				defer recoverHook(ctx, PhaseStartup, &err)
+				return next(ctx, errCh)
+			}
+		},
+		Shutdown: func(next types.ShutdownHook) types.ShutdownHook {
+			return func(ctx context.Context) (err error) {
+				defer recoverHook(ctx, PhaseShutdown, &err)
+				return next(ctx)
+			}
+		},
+	}
+}
+
+func recoverHook(ctx context.Context, phase Phase, err *error) {
+	if r := recover(); r != nil {
+		*err = errors.Wrapf(types.NewPanicError(r), "%s hook of service %q", phase, types.ServiceName(ctx))
+	}
+}
@@ -0,0 +1,56 @@
+package lifecycle
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMiddlewares(t *testing.T) {
+	var calls []string
+	trace := func(name string) types.Middleware {
+		return types.Middleware{
+			Startup: func(next types.StartupHook) types.StartupHook {
+				return func(ctx context.Context, errCh chan<- error) error {
+					calls = append(calls, name+":"+types.ServiceName(ctx))
+					return next(ctx, errCh)
+				}
+			},
+		}
+	}
+	var timings []Phase
+	cfg := DefaultConfig
+	cfg.Middlewares = []types.Middleware{
+		TimingMiddleware(func(service string, phase Phase, _ time.Duration, err error) {
+			require.Equal(t, "svc", service)
+			timings = append(timings, phase)
+		}),
+		RecoveryMiddleware(),
+		trace("global"),
+	}
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterService(types.ServiceConfig{
+		Name:        "svc",
+		Middlewares: []types.Middleware{trace("service")},
+		StartupHook: func(context.Context, chan<- error) error {
+			calls = append(calls, "hook")
+			return nil
+		},
+		ShutdownHook: func(context.Context) error {
+			panic("boom")
+		},
+	})
+	require.NoError(t, lf.Start())
+	require.Equal(t, []string{"global:svc", "service:svc", "hook"}, calls)
+
+	err := lf.Stop()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `shutdown hook of service "svc": panic: boom`)
+	var panicErr *types.PanicError
+	require.ErrorAs(t, err, &panicErr)
+	require.Equal(t, "boom", panicErr.Value)
+	require.Equal(t, []Phase{PhaseStartup, PhaseShutdown}, timings)
+}
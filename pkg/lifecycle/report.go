@@ -0,0 +1,156 @@
+package lifecycle
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
+)
+
+// StartupResult is a startup outcome of the service.
+type StartupResult string
+
+// Startup results.
+const (
+	// StartupOK service was started successfully.
+	StartupOK StartupResult = "ok"
+	// StartupFailed service startup failed.
+	StartupFailed StartupResult = "failed"
+	// StartupSkipped service was skipped by crash-loop protection.
+	StartupSkipped StartupResult = "skipped"
+	// StartupNotStarted service was not started due to previous errors or timeout.
+	StartupNotStarted StartupResult = "not started"
+)
+
+// ServiceStartup is a startup timing of the service.
+type ServiceStartup struct {
+	// Name of the service.
+	Name string
+	// DependsOn is a list of service dependencies.
+	DependsOn []string
+	// Wait is a time from the beginning of startup to the service hook invocation,
+	// it includes waiting for dependencies readiness.
+	Wait time.Duration
+	// Duration of the service startup.
+	Duration time.Duration
+	// Result of the service startup.
+	Result StartupResult
+	// Error of the service startup, if failed.
+	Error error
+}
+
+// StartupReport is a timing report of the last lifecycle startup.
+type StartupReport struct {
+	// Time when startup began.
+	Time time.Time
+	// Duration of the whole startup including rollback, if any.
+	Duration time.Duration
+	// Error of the startup, if any.
+	Error error
+	// Services startup timings in the order of startup.
+	Services []ServiceStartup
+	// CriticalPath is a chain of services that determined total startup time:
+	// it starts with the service finished last and follows its predecessor finished last.
+	// Services are started one by one, so predecessors of each service are its dependencies
+	// and the previous started service. Critical path is listed in the order of startup.
+	CriticalPath []string
+	// CriticalPathDuration is a sum of startup durations of services on critical path.
+	CriticalPathDuration time.Duration
+}
+
+// String formats report as a table.
+func (r StartupReport) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "startup took %s", r.Duration)
+	if r.Error != nil {
+		fmt.Fprintf(&sb, ", failed: %v", r.Error)
+	}
+	sb.WriteByte('\n')
+	tw := tabwriter.NewWriter(&sb, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SERVICE\tWAIT\tDURATION\tRESULT\tDEPENDS ON")
+	for _, s := range r.Services {
+		result := string(s.Result)
+		if s.Error != nil {
+			result += ": " + s.Error.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", s.Name, s.Wait, s.Duration, result, strings.Join(s.DependsOn, ","))
+	}
+	tw.Flush()
+	if len(r.CriticalPath) > 0 {
+		fmt.Fprintf(&sb, "critical path (%s): %s\n", r.CriticalPathDuration, strings.Join(r.CriticalPath, " -> "))
+	}
+	return sb.String()
+}
+
+// criticalPath computes critical path of started services. Predecessors
+// of each service are services with the names from DependsOn list
+// and the previous started service, since startup is sequential.
+func criticalPath(services []ServiceStartup) ([]string, time.Duration) {
+	byName := make(map[string]int, len(services))
+	last := -1
+	for i, s := range services {
+		if s.Result != StartupOK && s.Result != StartupFailed {
+			continue
+		}
+		byName[s.Name] = i
+		if last < 0 || finish(s) >= finish(services[last]) {
+			last = i
+		}
+	}
+	var path []string
+	var total time.Duration
+	visited := make(map[int]bool)
+	for cur := last; cur >= 0 && !visited[cur]; {
+		visited[cur] = true
+		s := services[cur]
+		path = append(path, s.Name)
+		total += s.Duration
+		next := -1
+		for i := cur - 1; i >= 0; i-- {
+			if _, ok := byName[services[i].Name]; ok {
+				next = i
+				break
+			}
+		}
+		for _, dep := range s.DependsOn {
+			if i, ok := byName[dep]; ok && i != cur && (next < 0 || finish(services[i]) > finish(services[next])) {
+				next = i
+			}
+		}
+		cur = next
+	}
+	for i, k := 0, len(path)-1; i < k; i, k = i+1, k-1 {
+		path[i], path[k] = path[k], path[i]
+	}
+	return path, total
+}
+
+func finish(s ServiceStartup) time.Duration {
+	return s.Wait + s.Duration
+}
+
+// logStartupReport logs startup report if startup took longer than configured threshold.
+func (l *Lifecycle) logStartupReport(r StartupReport) {
+	if l.config.SlowStartupThreshold <= 0 || r.Duration <= l.config.SlowStartupThreshold {
+		return
+	}
+	l.config.Logger.Log(logging.LevelWarn, "slow startup",
+		logging.Any("duration", r.Duration),
+		logging.Any("threshold", l.config.SlowStartupThreshold),
+		logging.String("critical_path", strings.Join(r.CriticalPath, " -> ")),
+		logging.Any("critical_path_duration", r.CriticalPathDuration))
+	for _, s := range r.Services {
+		fields := []logging.Field{
+			logging.String("service", s.Name),
+			logging.Any("wait", s.Wait),
+			logging.Any("duration", s.Duration),
+			logging.String("result", string(s.Result)),
+		}
+		if s.Error != nil {
+			fields = append(fields, logging.Err(s.Error))
+		}
+		l.config.Logger.Log(logging.LevelWarn, "service startup timing", fields...)
+	}
+}
@@ -0,0 +1,100 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCriticalPath(t *testing.T) {
+	ms := time.Millisecond
+	for _, tc := range []struct {
+		name     string
+		services []ServiceStartup
+		path     []string
+		duration time.Duration
+	}{
+		{"empty", nil, nil, 0},
+		{"sequential", []ServiceStartup{
+			{Name: "db", Duration: 10 * ms, Result: StartupOK},
+			{Name: "cache", Wait: 10 * ms, Duration: 5 * ms, Result: StartupOK},
+			{Name: "web", Wait: 15 * ms, Duration: 1 * ms, Result: StartupOK},
+		}, []string{"db", "cache", "web"}, 16 * ms},
+		{"dependencies", []ServiceStartup{
+			{Name: "db", Duration: 10 * ms, Result: StartupOK},
+			{Name: "cache", Wait: 10 * ms, Duration: 5 * ms, Result: StartupOK},
+			{Name: "queue", Wait: 15 * ms, Duration: 20 * ms, Result: StartupOK},
+			{Name: "web", Wait: 35 * ms, Duration: 1 * ms, DependsOn: []string{"db", "queue"}, Result: StartupOK},
+		}, []string{"db", "cache", "queue", "web"}, 36 * ms},
+		{"independent", []ServiceStartup{
+			{Name: "db", Duration: 10 * ms, Result: StartupOK},
+			{Name: "cache", Wait: 10 * ms, Duration: 5 * ms, DependsOn: []string{"db"}, Result: StartupOK},
+			{Name: "web", Wait: 15 * ms, Duration: 1 * ms, DependsOn: []string{"db"}, Result: StartupOK},
+		}, []string{"db", "cache", "web"}, 16 * ms},
+		{"failed", []ServiceStartup{
+			{Name: "db", Duration: 10 * ms, Result: StartupOK},
+			{Name: "web", Wait: 10 * ms, Duration: 1 * ms, Result: StartupFailed},
+			{Name: "worker", Result: StartupNotStarted},
+		}, []string{"db", "web"}, 11 * ms},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path, duration := criticalPath(tc.services)
+			require.Equal(t, tc.path, path)
+			require.Equal(t, tc.duration, duration)
+		})
+	}
+}
+
+func TestStartupReport(t *testing.T) {
+	var buf syncBuffer
+	cfg := DefaultConfig
+	cfg.Logger = NewStdLogger(&buf)
+	cfg.SlowStartupThreshold = time.Millisecond
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("db", func(context.Context, chan<- error) error {
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	})
+	lf.RegisterService(types.ServiceConfig{
+		Name:        "web",
+		DependsOn:   []string{"db"},
+		StartupHook: func(context.Context, chan<- error) error { return nil },
+	})
+	require.Empty(t, lf.StartupReport().Services)
+	require.NoError(t, lf.Start())
+
+	report := lf.StartupReport()
+	require.NoError(t, report.Error)
+	require.Len(t, report.Services, 2)
+	require.Equal(t, StartupOK, report.Services[0].Result)
+	require.GreaterOrEqual(t, report.Services[0].Duration, 5*time.Millisecond)
+	require.GreaterOrEqual(t, report.Services[1].Wait, report.Services[0].Duration)
+	require.Equal(t, []string{"db", "web"}, report.CriticalPath)
+	require.GreaterOrEqual(t, report.Duration, report.CriticalPathDuration)
+	require.Contains(t, report.String(), "critical path")
+	require.Contains(t, buf.String(), "WARN slow startup")
+	require.Contains(t, buf.String(), "service startup timing service=web")
+}
+
+// syncBuffer is a buffer safe for concurrent logging.
+type syncBuffer struct {
+	mx  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mx.Lock()
+	defer b.mx.Unlock()
+	return b.buf.String()
+}
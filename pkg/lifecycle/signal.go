@@ -5,10 +5,12 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+
+	"github.com/g4s8/go-lifecycle/pkg/logging"
 )
 
 // SignalHandler is an OS signal handler that can be used to trigger a
-// lifecycle shutdown.
+// lifecycle shutdown. In batch mode it also stops waiting when lifecycle is done.
 type SignalHandler struct {
 	lifecycle *Lifecycle
 	logger    Logger
@@ -49,12 +51,29 @@ func (h *SignalHandler) Start(cfg ShutdownConfig) {
 		defer close(h.waitCh)
 
 		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-		<-c
+		signal.Notify(c, h.signals...)
+		defer signal.Stop(c)
+		var sig os.Signal
+		select {
+		case sig = <-c:
+		case <-h.lifecycle.Done():
+			err := h.lifecycle.Err()
+			if err != nil {
+				h.waitCh <- err
+			}
+			if cfg.ExitOnShutdown {
+				if err != nil {
+					os.Exit(1)
+				}
+				os.Exit(0)
+			}
+			return
+		}
+		h.logger.Log(logging.LevelInfo, "received signal, stopping lifecycle", logging.Any("signal", sig))
 		ctx, cancel := context.WithTimeout(context.Background(), h.lifecycle.config.ShutdownTimeout)
 		defer cancel()
-		if err := h.lifecycle.stop(ctx, false); err != nil {
-			h.logger.Printf("failed to stop lifecycle: %v", err)
+		if _, err := h.lifecycle.stop(ctx, PhaseShutdown, "signal: "+sig.String()); err != nil {
+			h.logger.Log(logging.LevelError, "failed to stop lifecycle", logging.Err(err))
 			h.waitCh <- err
 			if cfg.ExitOnShutdown {
 				os.Exit(1)
@@ -3,6 +3,7 @@ package lifecycle
 import (
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/g4s8/go-lifecycle/pkg/types"
 )
@@ -17,6 +18,35 @@ type ServiceState struct {
 	Status types.ServiceStatus
 	// Service error, if any.
 	Error error
+	// Final is true if service failed and it won't be restarted.
+	Final bool
+	// Service criticality, specified by user.
+	Criticality types.ServiceCriticality
+	// Since is a time when service entered current status.
+	Since time.Time
+	// StartDuration is a duration of the last service startup.
+	StartDuration time.Duration
+	// StopDuration is a duration of the last service shutdown.
+	StopDuration time.Duration
+	// Restarts is a number of restart attempts after runtime errors.
+	Restarts int
+	// NextRestart is a time of the next scheduled restart attempt, if any.
+	NextRestart time.Time
+	// IgnoredErrors is a number of ignorable runtime errors reported by service.
+	IgnoredErrors int
+	// DegradedReason is a reason of degraded status reported with types.Reporter.
+	DegradedReason string
+	// LastHeartbeat is a time of the last heartbeat reported with types.Reporter.
+	LastHeartbeat time.Time
+	// Errors are the last service errors, oldest first.
+	// The number of errors is limited by Config.ErrorsHistorySize.
+	Errors []ErrorRecord
+}
+
+// ErrorRecord is a service error with the time when it occurred.
+type ErrorRecord struct {
+	Time  time.Time
+	Error error
 }
 
 func (s ServiceState) String() string {
@@ -2,23 +2,62 @@ package lifecycle
 
 import (
 	"sync"
+	"sync/atomic"
 )
 
+// OverflowPolicy defines subscription behavior when its buffer is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks publisher until subscriber receives pending items.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest pending item to deliver a new one.
+	OverflowDropOldest
+	// OverflowDropNewest drops a new item if the buffer is full.
+	OverflowDropNewest
+	// OverflowKeepLatest keeps only the latest pending item, buffer size is ignored.
+	OverflowKeepLatest
+)
+
+// SubscriptionConfig is a configuration of subscription delivery.
+//
+// Each subscription has its own buffer, items are forwarded from the buffer
+// to subscriber channel asynchronously, so slow subscribers don't block publisher
+// unless OverflowBlock policy is used.
+type SubscriptionConfig struct {
+	// BufferSize is a number of pending items kept by subscription.
+	BufferSize int
+	// Overflow is a policy applied when the buffer is full.
+	Overflow OverflowPolicy
+}
+
+// DefaultSubscriptionConfig is a default subscription configuration.
+var DefaultSubscriptionConfig = SubscriptionConfig{
+	BufferSize: 64,
+	Overflow:   OverflowDropOldest,
+}
+
 type publisher[T any] struct {
 	subscriptions []*subscription[T]
 
+	// keepLast enables delivery of last published item to new subscribers.
+	keepLast bool
+	// onDrop is called with total number of dropped items of subscription
+	// on each drop, it could be nil.
+	onDrop  func(total uint64)
 	last    T
 	hasLast bool
 	mx      sync.Mutex
 }
 
-func (p *publisher[T]) subscribe(subscriber chan<- T) Subscription {
+// subscribe to publisher, optional filter could be used to skip items.
+func (p *publisher[T]) subscribe(subscriber chan<- T, cfg SubscriptionConfig, filter func(T) bool) Subscription {
 	p.mx.Lock()
 	defer p.mx.Unlock()
-	sub := newSubscription(subscriber, p)
+	sub := newSubscription(subscriber, cfg, filter, p)
 	p.subscriptions = append(p.subscriptions, sub)
 	if p.hasLast {
-		sub.deliver(p.last)
+		sub.replay(p.last)
 	}
 	return sub
 }
@@ -40,36 +79,136 @@ func (p *publisher[T]) publish(item T) {
 	for _, sub := range p.subscriptions {
 		sub.deliver(item)
 	}
-	p.last = item
-	p.hasLast = true
+	if p.keepLast {
+		p.last = item
+		p.hasLast = true
+	}
 }
 
 // Subscription for publisher.
 type Subscription interface {
 	// Cancel this subscription.
 	Cancel()
+	// Dropped returns total number of items dropped by overflow policy.
+	Dropped() uint64
 }
 
 type subscription[T any] struct {
 	subscriber chan<- T
+	filter     func(T) bool
+	overflow   OverflowPolicy
+	queue      chan T
+	dropped    uint64
 	cancelCh   chan struct{}
+	cancelOnce sync.Once
+	doneCh     chan struct{}
 	publisher  *publisher[T]
 }
 
-func newSubscription[T any](subscriber chan<- T, publisher *publisher[T]) *subscription[T] {
-	return &subscription[T]{subscriber: subscriber, cancelCh: make(chan struct{}), publisher: publisher}
+func newSubscription[T any](subscriber chan<- T, cfg SubscriptionConfig, filter func(T) bool,
+	publisher *publisher[T],
+) *subscription[T] {
+	size := cfg.BufferSize
+	if size <= 0 || cfg.Overflow == OverflowKeepLatest {
+		size = 1
+	}
+	sub := &subscription[T]{
+		subscriber: subscriber,
+		filter:     filter,
+		overflow:   cfg.Overflow,
+		queue:      make(chan T, size),
+		cancelCh:   make(chan struct{}),
+		doneCh:     make(chan struct{}),
+		publisher:  publisher,
+	}
+	go sub.forward()
+	return sub
+}
+
+// forward pending items from subscription buffer to subscriber.
+func (s *subscription[T]) forward() {
+	defer close(s.doneCh)
+	for {
+		select {
+		case item := <-s.queue:
+			select {
+			case s.subscriber <- item:
+			case <-s.cancelCh:
+				return
+			}
+		case <-s.cancelCh:
+			return
+		}
+	}
+}
+
+// replay last item to new subscriber, it tries to send it directly
+// to subscriber channel first to make it available immediately.
+func (s *subscription[T]) replay(item T) {
+	if s.filter != nil && !s.filter(item) {
+		return
+	}
+	select {
+	case s.subscriber <- item:
+	default:
+		s.deliver(item)
+	}
 }
 
 func (s *subscription[T]) deliver(item T) {
+	if s.filter != nil && !s.filter(item) {
+		return
+	}
 	select {
 	case <-s.cancelCh:
 		return
 	default:
 	}
-	s.subscriber <- item
+	switch s.overflow {
+	case OverflowBlock:
+		select {
+		case s.queue <- item:
+		case <-s.cancelCh:
+		}
+	case OverflowDropNewest:
+		select {
+		case s.queue <- item:
+		default:
+			s.drop()
+		}
+	default:
+		for {
+			select {
+			case s.queue <- item:
+				return
+			default:
+			}
+			select {
+			case <-s.queue:
+				s.drop()
+			default:
+			}
+		}
+	}
+}
+
+func (s *subscription[T]) drop() {
+	total := atomic.AddUint64(&s.dropped, 1)
+	if s.publisher.onDrop != nil {
+		s.publisher.onDrop(total)
+	}
+}
+
+func (s *subscription[T]) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
 }
 
+// Cancel subscription and wait until pending items forwarding is stopped,
+// nothing is sent to subscriber channel after Cancel returns, so it's safe to close it.
 func (s *subscription[T]) Cancel() {
-	close(s.cancelCh)
-	s.publisher.unsubscribe(s)
+	s.cancelOnce.Do(func() {
+		close(s.cancelCh)
+		s.publisher.unsubscribe(s)
+	})
+	<-s.doneCh
 }
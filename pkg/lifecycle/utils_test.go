@@ -0,0 +1,114 @@
+package lifecycle
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublisherOverflow(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		overflow  OverflowPolicy
+		lastItem  bool
+		firstItem bool
+	}{
+		{"drop oldest", OverflowDropOldest, true, false},
+		{"drop newest", OverflowDropNewest, false, true},
+		{"keep latest", OverflowKeepLatest, true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var pub publisher[int]
+			ch := make(chan int)
+			sub := pub.subscribe(ch, SubscriptionConfig{BufferSize: 2, Overflow: tc.overflow}, nil)
+			defer sub.Cancel()
+
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for i := 1; i <= 10; i++ {
+					pub.publish(i)
+				}
+			}()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("publisher is blocked by slow subscriber")
+			}
+			require.Greater(t, sub.Dropped(), uint64(0))
+
+			var received []int
+			for {
+				select {
+				case item := <-ch:
+					received = append(received, item)
+					continue
+				case <-time.After(time.Millisecond * 50):
+				}
+				break
+			}
+			require.NotEmpty(t, received)
+			if tc.firstItem {
+				require.Equal(t, 1, received[0])
+				require.NotContains(t, received, 10)
+			}
+			if tc.lastItem {
+				require.Equal(t, 10, received[len(received)-1])
+			}
+		})
+	}
+	t.Run("block", func(t *testing.T) {
+		var pub publisher[int]
+		ch := make(chan int)
+		sub := pub.subscribe(ch, SubscriptionConfig{BufferSize: 1, Overflow: OverflowBlock}, nil)
+		defer sub.Cancel()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for i := 1; i <= 5; i++ {
+				pub.publish(i)
+			}
+		}()
+		for i := 1; i <= 5; i++ {
+			select {
+			case item := <-ch:
+				require.Equal(t, i, item)
+			case <-time.After(time.Second):
+				t.Fatal("item was not delivered")
+			}
+		}
+		<-done
+		require.Zero(t, sub.Dropped())
+	})
+	t.Run("replay last", func(t *testing.T) {
+		pub := publisher[int]{keepLast: true}
+		pub.publish(42)
+		ch := make(chan int, 1)
+		sub := pub.subscribe(ch, DefaultSubscriptionConfig, nil)
+		defer sub.Cancel()
+		select {
+		case item := <-ch:
+			require.Equal(t, 42, item)
+		default:
+			t.Fatal("last item was not replayed")
+		}
+	})
+}
+
+func TestSubscriptionCancel(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		var pub publisher[int]
+		ch := make(chan int, 1)
+		sub := pub.subscribe(ch, SubscriptionConfig{BufferSize: 16, Overflow: OverflowDropOldest}, nil)
+		for j := 0; j < 16; j++ {
+			pub.publish(j)
+		}
+		sub.Cancel()
+		// subscriber channel is closed right after cancel with items still queued,
+		// forwarding must not send to it anymore
+		close(ch)
+		pub.publish(16)
+	}
+}
@@ -0,0 +1,96 @@
+package lifecycle
+
+import (
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrDuplicateService is matched by ValidationError if services have the same name.
+	ErrDuplicateService = errors.New("duplicate service name")
+	// ErrInvalidService is matched by ValidationError if service config is invalid.
+	ErrInvalidService = errors.New("invalid service config")
+	// ErrNoJobs is matched by ValidationError if batch mode is enabled without jobs.
+	ErrNoJobs = errors.New("batch mode without jobs")
+	// ErrInvalidConfig is matched by ValidationError if lifecycle config is invalid.
+	ErrInvalidConfig = errors.New("invalid lifecycle config")
+)
+
+// Validate services configuration, it returns ValidationError with all
+// problems found. Validate is called by Start before services are started.
+func (l *Lifecycle) Validate() error {
+	l.mx.RLock()
+	defer l.mx.RUnlock()
+	return l.validate()
+}
+
+func (l *Lifecycle) validate() error {
+	var problems []error
+	invalid := func(name, format string, args ...interface{}) {
+		problems = append(problems, errors.Wrapf(ErrInvalidService,
+			"service %q: "+format, append([]interface{}{name}, args...)...))
+	}
+	ids := make(map[string]int, len(l.configs))
+	var jobs int
+	for i, cfg := range l.configs {
+		if cfg.Job != nil {
+			jobs++
+		}
+		if cfg.Name == "" {
+			invalid(cfg.Name, "empty name")
+		} else if _, ok := ids[cfg.Name]; ok {
+			problems = append(problems, errors.Wrapf(ErrDuplicateService, "service %q", cfg.Name))
+		} else {
+			ids[cfg.Name] = i
+		}
+		if cfg.StartupHook == nil && cfg.ShutdownHook == nil && cfg.Job == nil && cfg.Run == nil {
+			invalid(cfg.Name, "no hooks, job or run function")
+		}
+		if cfg.Job != nil && cfg.Run != nil {
+			invalid(cfg.Name, "both job and run function")
+		}
+		if cfg.PauseHook != nil && cfg.ResumeHook == nil {
+			invalid(cfg.Name, "pause hook without resume hook")
+		}
+		if cfg.ResumeHook != nil && cfg.PauseHook == nil {
+			invalid(cfg.Name, "resume hook without pause hook")
+		}
+		if cfg.RestartPolicy.RestartCount < 0 {
+			invalid(cfg.Name, "negative restart count %d", cfg.RestartPolicy.RestartCount)
+		}
+		if cfg.RestartPolicy.RestartDelay < 0 {
+			invalid(cfg.Name, "negative restart delay %s", cfg.RestartPolicy.RestartDelay)
+		}
+		if cfg.HealthCheckInterval < 0 {
+			invalid(cfg.Name, "negative health check interval %s", cfg.HealthCheckInterval)
+		}
+		if cfg.Criticality != types.ServiceCritical && cfg.Criticality != types.ServiceOptional {
+			invalid(cfg.Name, "unknown criticality %s", cfg.Criticality)
+		}
+	}
+	for i, cfg := range l.configs {
+		for _, dep := range cfg.DependsOn {
+			id, ok := ids[dep]
+			switch {
+			case !ok:
+				problems = append(problems, errors.Wrapf(ErrServiceNotFound,
+					"service %q: dependency %q", cfg.Name, dep))
+			case id == i:
+				invalid(cfg.Name, "depends on itself")
+			case cfg.WaitDependencies && id > i:
+				invalid(cfg.Name, "dependency %q is registered after the service", dep)
+			}
+		}
+	}
+	if l.config.Batch && jobs == 0 {
+		// batch would be finished right after startup
+		problems = append(problems, ErrNoJobs)
+	}
+	if l.config.CrashLoop != nil && l.config.CrashLoop.StatePath == "" {
+		problems = append(problems, errors.Wrap(ErrInvalidConfig, "empty crash loop state path"))
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/g4s8/go-lifecycle/pkg/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	nop := func(context.Context, chan<- error) error { return nil }
+	var log bytes.Buffer
+	cfg := DefaultConfig
+	cfg.Logger = NewStdLogger(&log)
+	lf := newTestLifecycle(t, cfg)
+	lf.RegisterStartupHook("web", nop)
+	lf.RegisterStartupHook("web", nop)
+	require.Contains(t, log.String(), "ERROR duplicate service name service=web id=0")
+	lf.RegisterService(types.ServiceConfig{Name: "empty"})
+	lf.RegisterService(types.ServiceConfig{
+		Name:          "worker",
+		StartupHook:   nop,
+		RestartPolicy: types.ServiceRestartPolicy{RestartOnFailure: true, RestartCount: -1},
+		DependsOn:     []string{"db"},
+	})
+	lf.RegisterService(types.ServiceConfig{
+		Name: "migrations",
+		Job:  func(context.Context) error { return nil },
+		Run:  func(context.Context) error { return nil },
+	})
+	lf.RegisterService(types.ServiceConfig{
+		Name:        "consumer",
+		StartupHook: nop,
+		ResumeHook:  func(context.Context) error { return nil },
+	})
+
+	err := lf.Validate()
+	var validationErr *ValidationError
+	require.ErrorAs(t, err, &validationErr)
+	require.Len(t, validationErr.Problems, 6)
+	require.ErrorIs(t, err, ErrDuplicateService)
+	require.ErrorIs(t, err, ErrInvalidService)
+	require.ErrorIs(t, err, ErrServiceNotFound)
+	require.Contains(t, err.Error(), `service "worker": negative restart count -1`)
+	require.Contains(t, err.Error(), `service "consumer": resume hook without pause hook`)
+
+	require.ErrorIs(t, lf.Start(), ErrDuplicateService)
+	for _, st := range lf.Statuses() {
+		require.Equal(t, types.ServiceStatusInit, st.Status, "services should not be started")
+	}
+}
+
+func TestValidateDependencies(t *testing.T) {
+	nop := func(context.Context, chan<- error) error { return nil }
+	lf := newTestLifecycle(t, DefaultConfig)
+	lf.RegisterService(types.ServiceConfig{
+		Name:             "web",
+		StartupHook:      nop,
+		DependsOn:        []string{"db", "web"},
+		WaitDependencies: true,
+	})
+	lf.RegisterStartupHook("db", nop)
+
+	err := lf.Validate()
+	require.ErrorIs(t, err, ErrInvalidService)
+	require.Contains(t, err.Error(), `service "web": dependency "db" is registered after the service`)
+	require.Contains(t, err.Error(), `service "web": depends on itself`)
+}
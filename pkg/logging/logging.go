@@ -0,0 +1,143 @@
+// Package logging provides leveled structured logging interface used by
+// lifecycle components, and adapters for `log/slog` and Printf style loggers.
+package logging
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Level is a logging level, its values are compatible with `slog.Level`.
+type Level int
+
+// Logging levels.
+const (
+	LevelDebug Level = -4
+	LevelInfo  Level = 0
+	LevelWarn  Level = 4
+	LevelError Level = 8
+)
+
+func (l Level) String() string {
+	switch l {
+	case LevelDebug:
+		return "DEBUG"
+	case LevelInfo:
+		return "INFO"
+	case LevelWarn:
+		return "WARN"
+	case LevelError:
+		return "ERROR"
+	default:
+		return "LEVEL(" + strconv.Itoa(int(l)) + ")"
+	}
+}
+
+// Field is a structured log attribute.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+// Any creates log field with any value.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// String creates log field with string value.
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Int creates log field with int value.
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err creates log field with error value and "error" key.
+func Err(err error) Field {
+	return Field{Key: "error", Value: err}
+}
+
+// Logger is a leveled structured logger.
+type Logger interface {
+	// Log message with level and fields.
+	Log(level Level, msg string, fields ...Field)
+}
+
+type nopLogger struct{}
+
+func (nopLogger) Log(Level, string, ...Field) {}
+
+// Nop is a no-op logger.
+var Nop Logger = nopLogger{}
+
+type withLogger struct {
+	logger Logger
+	fields []Field
+}
+
+// With returns logger which adds fields to each message.
+func With(logger Logger, fields ...Field) Logger {
+	if len(fields) == 0 {
+		return logger
+	}
+	if w, ok := logger.(*withLogger); ok {
+		return &withLogger{
+			logger: w.logger,
+			fields: append(append([]Field(nil), w.fields...), fields...),
+		}
+	}
+	return &withLogger{logger: logger, fields: fields}
+}
+
+func (l *withLogger) Log(level Level, msg string, fields ...Field) {
+	all := make([]Field, 0, len(l.fields)+len(fields))
+	all = append(all, l.fields...)
+	all = append(all, fields...)
+	l.logger.Log(level, msg, all...)
+}
+
+// PrintfLogger is a Printf style logger, e.g. `*log.Logger`.
+type PrintfLogger interface {
+	Printf(format string, v ...interface{})
+}
+
+type printfLogger struct {
+	out PrintfLogger
+	min Level
+}
+
+// FromPrintf adapts Printf style logger to Logger, messages below
+// min level are discarded. Messages are formatted with Format.
+func FromPrintf(out PrintfLogger, min Level) Logger {
+	return &printfLogger{out: out, min: min}
+}
+
+func (l *printfLogger) Log(level Level, msg string, fields ...Field) {
+	if level < l.min {
+		return
+	}
+	l.out.Printf("%s", Format(level, msg, fields...))
+}
+
+// Format formats log message as single line: level, message and
+// fields as `key=value` pairs, values with spaces are quoted.
+func Format(level Level, msg string, fields ...Field) string {
+	var sb strings.Builder
+	sb.WriteString(level.String())
+	sb.WriteByte(' ')
+	sb.WriteString(msg)
+	for _, f := range fields {
+		sb.WriteByte(' ')
+		sb.WriteString(f.Key)
+		sb.WriteByte('=')
+		val := fmt.Sprint(f.Value)
+		if val == "" || strings.ContainsAny(val, " \t\n\"=") {
+			val = strconv.Quote(val)
+		}
+		sb.WriteString(val)
+	}
+	return sb.String()
+}
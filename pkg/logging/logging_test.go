@@ -0,0 +1,25 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormat(t *testing.T) {
+	line := Format(LevelWarn, "service failed",
+		String("service", "web"), Int("attempt", 2), Err(errors.New("bind: address in use")))
+	require.Equal(t, `WARN service failed service=web attempt=2 error="bind: address in use"`, line)
+	require.Equal(t, "LEVEL(2)", Level(2).String())
+}
+
+func TestFromPrintf(t *testing.T) {
+	var buf bytes.Buffer
+	logger := With(FromPrintf(log.New(&buf, "", 0), LevelInfo), String("service", "web"))
+	logger.Log(LevelDebug, "skipped")
+	logger.Log(LevelInfo, "started", Int("id", 1))
+	require.Equal(t, "INFO started service=web id=1\n", buf.String())
+}
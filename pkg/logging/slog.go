@@ -0,0 +1,30 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+type slogLogger struct {
+	logger *slog.Logger
+}
+
+// FromSlog adapts `slog.Logger` to Logger, fields are converted to slog attributes.
+func FromSlog(logger *slog.Logger) Logger {
+	return &slogLogger{logger: logger}
+}
+
+func (l *slogLogger) Log(level Level, msg string, fields ...Field) {
+	ctx := context.Background()
+	lvl := slog.Level(level)
+	if !l.logger.Enabled(ctx, lvl) {
+		return
+	}
+	attrs := make([]slog.Attr, len(fields))
+	for i, f := range fields {
+		attrs[i] = slog.Any(f.Key, f.Value)
+	}
+	l.logger.LogAttrs(ctx, lvl, msg, attrs...)
+}
@@ -0,0 +1,28 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromSlog(t *testing.T) {
+	var buf bytes.Buffer
+	handler := slog.NewTextHandler(&buf, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+		ReplaceAttr: func(_ []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	logger := FromSlog(slog.New(handler))
+	logger.Log(LevelDebug, "skipped")
+	logger.Log(LevelWarn, "restarting", String("service", "web"), Int("attempt", 2))
+	require.Equal(t, "level=WARN msg=restarting service=web attempt=2\n", buf.String())
+}
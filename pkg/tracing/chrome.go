@@ -0,0 +1,173 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+var _ Tracer = (*ChromeTracer)(nil)
+
+// ChromeTracer records spans in memory and exports them as Chrome trace-event
+// JSON, which could be opened in `chrome://tracing` or https://ui.perfetto.dev.
+//
+// Spans are placed to lanes (threads in trace viewer): child span shares the lane
+// of its parent, concurrent spans are placed to different lanes, so the trace
+// shows which operations ran in parallel.
+type ChromeTracer struct {
+	start time.Time
+
+	mx     sync.Mutex
+	lanes  [][]*chromeSpan
+	events []chromeEvent
+}
+
+type chromeEvent struct {
+	Name  string                 `json:"name"`
+	Phase string                 `json:"ph"`
+	Time  int64                  `json:"ts"`
+	Dur   int64                  `json:"dur,omitempty"`
+	PID   int                    `json:"pid"`
+	TID   int                    `json:"tid"`
+	Args  map[string]interface{} `json:"args,omitempty"`
+}
+
+type chromeSpan struct {
+	tracer *ChromeTracer
+	name   string
+	start  time.Time
+	lane   int
+	args   map[string]interface{}
+	once   sync.Once
+}
+
+type chromeSpanKey struct{}
+
+// NewChromeTracer creates new tracer, timestamps of the trace are relative to its creation time.
+func NewChromeTracer() *ChromeTracer {
+	return &ChromeTracer{start: time.Now()}
+}
+
+func (t *ChromeTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	parent, _ := ctx.Value(chromeSpanKey{}).(*chromeSpan)
+	span := &chromeSpan{
+		tracer: t,
+		name:   name,
+		start:  time.Now(),
+		args:   make(map[string]interface{}, len(attrs)),
+	}
+	span.SetAttributes(attrs...)
+
+	t.mx.Lock()
+	span.lane = t.allocLane(parent)
+	t.lanes[span.lane] = append(t.lanes[span.lane], span)
+	t.mx.Unlock()
+	return context.WithValue(ctx, chromeSpanKey{}, span), span
+}
+
+// allocLane returns parent lane if parent is the innermost open span of the lane,
+// or the first lane without open spans. Lock should be held by caller.
+func (t *ChromeTracer) allocLane(parent *chromeSpan) int {
+	if parent != nil {
+		if open := t.lanes[parent.lane]; len(open) > 0 && open[len(open)-1] == parent {
+			return parent.lane
+		}
+	}
+	for i, open := range t.lanes {
+		if len(open) == 0 {
+			return i
+		}
+	}
+	t.lanes = append(t.lanes, nil)
+	return len(t.lanes) - 1
+}
+
+func (s *chromeSpan) SetAttributes(attrs ...Attribute) {
+	s.tracer.mx.Lock()
+	defer s.tracer.mx.Unlock()
+	for _, a := range attrs {
+		s.args[a.Key] = a.Value
+	}
+}
+
+func (s *chromeSpan) End(err error) {
+	s.once.Do(func() {
+		end := time.Now()
+		t := s.tracer
+		t.mx.Lock()
+		defer t.mx.Unlock()
+
+		if err != nil {
+			s.args["outcome"] = "error"
+			s.args["error"] = err.Error()
+		} else {
+			s.args["outcome"] = "ok"
+		}
+		open := t.lanes[s.lane]
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i] == s {
+				t.lanes[s.lane] = append(open[:i], open[i+1:]...)
+				break
+			}
+		}
+		dur := end.Sub(s.start).Microseconds()
+		if dur == 0 {
+			dur = 1
+		}
+		t.events = append(t.events, chromeEvent{
+			Name:  s.name,
+			Phase: "X",
+			Time:  s.start.Sub(t.start).Microseconds(),
+			Dur:   dur,
+			PID:   1,
+			TID:   s.lane,
+			Args:  s.args,
+		})
+	})
+}
+
+// WriteTo writes all ended spans as Chrome trace-event JSON.
+func (t *ChromeTracer) WriteTo(w io.Writer) (int64, error) {
+	t.mx.Lock()
+	events := make([]chromeEvent, 0, len(t.events)+len(t.lanes)+1)
+	events = append(events, chromeEvent{
+		Name: "process_name", Phase: "M", PID: 1,
+		Args: map[string]interface{}{"name": "lifecycle"},
+	})
+	for i := range t.lanes {
+		events = append(events, chromeEvent{
+			Name: "thread_name", Phase: "M", PID: 1, TID: i,
+			Args: map[string]interface{}{"name": "lane " + strconv.Itoa(i)},
+		})
+	}
+	events = append(events, t.events...)
+	data, err := json.Marshal(struct {
+		TraceEvents     []chromeEvent `json:"traceEvents"`
+		DisplayTimeUnit string        `json:"displayTimeUnit"`
+	}{events, "ms"})
+	t.mx.Unlock()
+	if err != nil {
+		return 0, errors.Wrap(err, "encode trace")
+	}
+	n, err := w.Write(data)
+	return int64(n), err
+}
+
+// WriteFile writes trace to the file.
+func (t *ChromeTracer) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrap(err, "create trace file")
+	}
+	if _, err := t.WriteTo(f); err != nil {
+		f.Close()
+		return errors.Wrap(err, "write trace file")
+	}
+	return errors.Wrap(f.Close(), "close trace file")
+}
@@ -0,0 +1,48 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestChromeTracer(t *testing.T) {
+	tracer := NewChromeTracer()
+	ctx, root := tracer.Start(context.Background(), "startup")
+	_, first := tracer.Start(ctx, "first", Attr("service", "db"))
+	first.End(nil)
+	_, second := tracer.Start(ctx, "second")
+	// concurrent span of the same parent is placed to another lane
+	_, parallel := tracer.Start(ctx, "parallel")
+	parallel.End(nil)
+	second.End(errors.New("failed"))
+	root.End(nil)
+
+	var buf bytes.Buffer
+	_, err := tracer.WriteTo(&buf)
+	require.NoError(t, err)
+	var trace struct {
+		TraceEvents []chromeEvent `json:"traceEvents"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+	spans := make(map[string]chromeEvent)
+	for _, ev := range trace.TraceEvents {
+		if ev.Phase == "X" {
+			spans[ev.Name] = ev
+		}
+	}
+	require.Len(t, spans, 4)
+	require.Equal(t, 0, spans["startup"].TID)
+	require.Equal(t, 0, spans["first"].TID)
+	require.Equal(t, 0, spans["second"].TID)
+	require.Equal(t, 1, spans["parallel"].TID)
+	require.Equal(t, "db", spans["first"].Args["service"])
+	require.Equal(t, "ok", spans["first"].Args["outcome"])
+	require.Equal(t, "error", spans["second"].Args["outcome"])
+	require.Equal(t, "failed", spans["second"].Args["error"])
+	require.GreaterOrEqual(t, spans["startup"].Dur, spans["second"].Dur)
+}
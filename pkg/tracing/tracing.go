@@ -0,0 +1,46 @@
+// Package tracing provides minimal tracing interface used by lifecycle
+// to trace startup and shutdown phases, hooks and restarts of services,
+// and Chrome trace-event exporter to view them in a browser.
+package tracing
+
+import "context"
+
+// Attribute is a span attribute.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// Attr creates span attribute.
+func Attr(key string, value interface{}) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans.
+type Tracer interface {
+	// Start new span, returned context should be used to start child spans.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a traced operation.
+type Span interface {
+	// SetAttributes adds attributes to span.
+	SetAttributes(attrs ...Attribute)
+	// End span with the outcome of the operation, nil error is a successful outcome.
+	End(err error)
+}
+
+type nopTracer struct{}
+
+func (nopTracer) Start(ctx context.Context, _ string, _ ...Attribute) (context.Context, Span) {
+	return ctx, nopSpan{}
+}
+
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attribute) {}
+
+func (nopSpan) End(error) {}
+
+// Nop is a no-op tracer.
+var Nop Tracer = nopTracer{}
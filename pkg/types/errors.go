@@ -0,0 +1,75 @@
+package types
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrorClass defines how service runtime error is handled.
+type ErrorClass int
+
+const (
+	// ErrorTransient (default) error is handled by service restart policy.
+	ErrorTransient ErrorClass = iota
+	// ErrorPermanent error fails the service without restart attempts.
+	ErrorPermanent
+	// ErrorIgnorable error is logged and counted, the service is not restarted.
+	ErrorIgnorable
+)
+
+func (c ErrorClass) String() string {
+	switch c {
+	case ErrorTransient:
+		return "transient"
+	case ErrorPermanent:
+		return "permanent"
+	case ErrorIgnorable:
+		return "ignorable"
+	default:
+		return "ErrorClass(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
+// ErrorClassifier classifies service runtime errors.
+type ErrorClassifier func(err error) ErrorClass
+
+type classifiedError struct {
+	err   error
+	class ErrorClass
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks error as permanent, the service reported this error
+// to error channel fails without restart attempts. It returns nil for nil error.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrorPermanent}
+}
+
+// Ignorable marks error as ignorable, the service reported this error
+// to error channel keeps running. It returns nil for nil error.
+func Ignorable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &classifiedError{err: err, class: ErrorIgnorable}
+}
+
+// ClassOf returns the class of error marked with Permanent or Ignorable,
+// and false if error is not marked.
+func ClassOf(err error) (ErrorClass, bool) {
+	var ce *classifiedError
+	if errors.As(err, &ce) {
+		return ce.class, true
+	}
+	return ErrorTransient, false
+}
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrGoroutineLeak is returned on service stop if its group goroutines
+// didn't return before shutdown timeout.
+var ErrGoroutineLeak = errors.New("goroutines leaked")
+
+// Group is a group of service goroutines managed by lifecycle.
+type Group interface {
+	// Go runs function in new goroutine labelled with service name. Its context
+	// is cancelled when service is stopped or restarted, and the service waits
+	// for the goroutine to return on stop. Returned error or panic is reported
+	// as a runtime error of the service, errors after cancellation are ignored.
+	// The goroutine is named after the function, see GoNamed.
+	Go(fn func(ctx context.Context) error)
+	// GoNamed runs function in new goroutine same as Go, the name is used
+	// in goroutine pprof label and in leak reports.
+	GoNamed(name string, fn func(ctx context.Context) error)
+}
+
+type groupKey struct{}
+
+// WithGroup returns context with goroutine group.
+func WithGroup(ctx context.Context, g Group) context.Context {
+	return context.WithValue(ctx, groupKey{}, g)
+}
+
+// GroupFrom returns goroutine group of the service from startup hook or service function
+// context. If context has no group, it returns a group which runs unmanaged goroutines.
+func GroupFrom(ctx context.Context) Group {
+	if g, ok := ctx.Value(groupKey{}).(Group); ok {
+		return g
+	}
+	return unmanagedGroup{}
+}
+
+type unmanagedGroup struct{}
+
+func (unmanagedGroup) Go(fn func(ctx context.Context) error) {
+	go func() {
+		_ = fn(context.Background())
+	}()
+}
+
+func (g unmanagedGroup) GoNamed(_ string, fn func(ctx context.Context) error) {
+	g.Go(fn)
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+)
+
+// PanicError is an error of recovered panic with the stack trace of panicking goroutine.
+type PanicError struct {
+	// Value passed to panic.
+	Value interface{}
+	// Stack trace of the panic.
+	Stack []byte
+}
+
+// NewPanicError creates panic error with current stack trace,
+// it should be called in deferred function which recovered the panic.
+func NewPanicError(value interface{}) *PanicError {
+	return &PanicError{Value: value, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns panic value if it's an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// Format formats error message, `%+v` format includes stack trace.
+func (e *PanicError) Format(s fmt.State, verb rune) {
+	switch {
+	case verb == 'v' && s.Flag('+'):
+		fmt.Fprintf(s, "%s\n%s", e.Error(), e.Stack)
+	case verb == 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		io.WriteString(s, e.Error())
+	}
+}
+
+// Go runs function in new goroutine and reports its error or panic to error channel,
+// it could be used in startup hook to run service goroutines:
+//
+//	func (s *Service) Start(ctx context.Context, errCh chan<- error) error {
+//		types.Go(errCh, s.serve)
+//		return nil
+//	}
+func Go(errCh chan<- error, fn func() error) {
+	go func() {
+		err := func() (err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					err = NewPanicError(r)
+				}
+			}()
+			return fn()
+		}()
+		if err != nil {
+			errCh <- err
+		}
+	}()
+}
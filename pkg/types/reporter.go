@@ -0,0 +1,58 @@
+package types
+
+import "context"
+
+// Reporter reports runtime state of the service to lifecycle manager.
+// Its methods never block and do nothing after the service is closed,
+// so it's safe to use it from service goroutines.
+type Reporter interface {
+	// Fail reports runtime error of the service, it's handled
+	// same as error sent to error channel of startup hook.
+	Fail(err error)
+	// Degraded reports that service is running but impaired,
+	// service status is changed to degraded.
+	Degraded(reason string)
+	// Recovered reports that service recovered from degraded status.
+	Recovered()
+	// Ready reports that service is ready to serve, it's required
+	// if ServiceConfig.ReadinessSignal is set.
+	Ready()
+	// Heartbeat reports that service is alive.
+	Heartbeat()
+}
+
+// StartFunc is a startup function of the service which reports its runtime state with reporter.
+type StartFunc func(ctx context.Context, r Reporter) error
+
+// ReporterHook adapts startup function to startup hook, the reporter
+// is taken from hook context with ReporterFrom.
+func ReporterHook(fn StartFunc) StartupHook {
+	return func(ctx context.Context, _ chan<- error) error {
+		return fn(ctx, ReporterFrom(ctx))
+	}
+}
+
+type reporterKey struct{}
+
+// WithReporter returns context with reporter.
+func WithReporter(ctx context.Context, r Reporter) context.Context {
+	return context.WithValue(ctx, reporterKey{}, r)
+}
+
+// ReporterFrom returns reporter from startup hook context, it's available in
+// startup hooks registered with lifecycle. It returns no-op reporter if
+// context has no reporter.
+func ReporterFrom(ctx context.Context) Reporter {
+	if r, ok := ctx.Value(reporterKey{}).(Reporter); ok {
+		return r
+	}
+	return nopReporter{}
+}
+
+type nopReporter struct{}
+
+func (nopReporter) Fail(error)      {}
+func (nopReporter) Degraded(string) {}
+func (nopReporter) Recovered()      {}
+func (nopReporter) Ready()          {}
+func (nopReporter) Heartbeat()      {}
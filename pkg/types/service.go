@@ -0,0 +1,88 @@
+package types
+
+import (
+	"context"
+	"fmt"
+)
+
+// Service is a lifecycle service implemented as a type. Its configuration
+// is built with NewServiceConfig from optional interfaces it implements: Named,
+// Dependent, RestartPolicyProvider, CriticalityProvider, Pausable, Reloader and HealthChecker.
+type Service interface {
+	// Start the service, it's called as a startup hook.
+	Start(ctx context.Context, r Reporter) error
+	// Stop the service, it's called as a shutdown hook.
+	Stop(ctx context.Context) error
+}
+
+// Named service provides its name, otherwise
+// the name of the service is its type name.
+type Named interface {
+	Name() string
+}
+
+// Dependent service provides names of services it depends on.
+type Dependent interface {
+	DependsOn() []string
+}
+
+// RestartPolicyProvider provides restart policy of the service,
+// otherwise DefaultRestartPolicy is used.
+type RestartPolicyProvider interface {
+	RestartPolicy() ServiceRestartPolicy
+}
+
+// CriticalityProvider provides criticality of the service,
+// services are critical by default.
+type CriticalityProvider interface {
+	Criticality() ServiceCriticality
+}
+
+// Pausable service could be paused and resumed without stopping.
+type Pausable interface {
+	Pause(ctx context.Context) error
+	Resume(ctx context.Context) error
+}
+
+// Reloader service could be reloaded without restart, e.g. to apply new configuration.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// HealthChecker service checks its health periodically, see ServiceConfig.HealthCheck.
+type HealthChecker interface {
+	CheckHealth(ctx context.Context) error
+}
+
+// NewServiceConfig builds configuration of the service from interfaces it implements.
+func NewServiceConfig(svc Service) ServiceConfig {
+	cfg := ServiceConfig{
+		Name:          fmt.Sprintf("%T", svc),
+		StartupHook:   ReporterHook(svc.Start),
+		ShutdownHook:  svc.Stop,
+		RestartPolicy: DefaultRestartPolicy,
+	}
+	if s, ok := svc.(Named); ok {
+		cfg.Name = s.Name()
+	}
+	if s, ok := svc.(Dependent); ok {
+		cfg.DependsOn = s.DependsOn()
+	}
+	if s, ok := svc.(RestartPolicyProvider); ok {
+		cfg.RestartPolicy = s.RestartPolicy()
+	}
+	if s, ok := svc.(CriticalityProvider); ok {
+		cfg.Criticality = s.Criticality()
+	}
+	if s, ok := svc.(Pausable); ok {
+		cfg.PauseHook = s.Pause
+		cfg.ResumeHook = s.Resume
+	}
+	if s, ok := svc.(Reloader); ok {
+		cfg.ReloadHook = s.Reload
+	}
+	if s, ok := svc.(HealthChecker); ok {
+		cfg.HealthCheck = s.CheckHealth
+	}
+	return cfg
+}
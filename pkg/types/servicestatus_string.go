@@ -14,11 +14,15 @@ func _() {
 	_ = x[ServiceStatusStopping-3]
 	_ = x[ServiceStatusStopped-4]
 	_ = x[ServiceStatusError-5]
+	_ = x[ServiceStatusReady-6]
+	_ = x[ServiceStatusDegraded-7]
+	_ = x[ServiceStatusPaused-8]
+	_ = x[ServiceStatusCompleted-9]
 }
 
-const _ServiceStatus_name = "InitStartingRunningStoppingStoppedError"
+const _ServiceStatus_name = "InitStartingRunningStoppingStoppedErrorReadyDegradedPausedCompleted"
 
-var _ServiceStatus_index = [...]uint8{0, 4, 12, 19, 27, 34, 39}
+var _ServiceStatus_index = [...]uint8{0, 4, 12, 19, 27, 34, 39, 44, 52, 58, 67}
 
 func (i ServiceStatus) String() string {
 	if i < 0 || i >= ServiceStatus(len(_ServiceStatus_index)-1) {
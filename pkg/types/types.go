@@ -3,6 +3,8 @@ package types
 
 import (
 	"context"
+	"fmt"
+	"strconv"
 	"time"
 )
 
@@ -16,6 +18,54 @@ type StartupHook func(context.Context, chan<- error) error
 // This hook is called with specified shutdown context.
 type ShutdownHook func(context.Context) error
 
+// Job is a function of one-shot service, it runs to completion in background.
+// Job context is cancelled when service is stopped.
+type Job func(context.Context) error
+
+// RunFunc is a function of run-style service, it runs in background
+// until its context is cancelled on service stop.
+type RunFunc func(context.Context) error
+
+// PauseHook is a hook that is called when running service is paused.
+type PauseHook func(context.Context) error
+
+// ResumeHook is a hook that is called when paused service is resumed.
+type ResumeHook func(context.Context) error
+
+// ReloadHook is a hook that is called to reload configuration of running service.
+type ReloadHook func(context.Context) error
+
+// HealthCheck is a periodic health check of running service,
+// service is degraded while the check fails.
+type HealthCheck func(context.Context) error
+
+// StartupMiddleware wraps startup hook to intercept its invocation.
+type StartupMiddleware func(next StartupHook) StartupHook
+
+// ShutdownMiddleware wraps shutdown hook to intercept its invocation.
+type ShutdownMiddleware func(next ShutdownHook) ShutdownHook
+
+// Middleware is an interceptor of service hooks, any of startup
+// and shutdown middlewares could be nil.
+type Middleware struct {
+	Startup  StartupMiddleware
+	Shutdown ShutdownMiddleware
+}
+
+type serviceNameKey struct{}
+
+// WithServiceName returns context with service name.
+func WithServiceName(ctx context.Context, name string) context.Context {
+	return context.WithValue(ctx, serviceNameKey{}, name)
+}
+
+// ServiceName returns service name from hook context, it's
+// available in hooks and middlewares registered with lifecycle.
+func ServiceName(ctx context.Context) string {
+	name, _ := ctx.Value(serviceNameKey{}).(string)
+	return name
+}
+
 // ServiceStatus represents current status of service.
 //
 //go:generate stringer -type=ServiceStatus -trimprefix=ServiceStatus
@@ -29,8 +79,40 @@ const (
 	ServiceStatusStopping
 	ServiceStatusStopped
 	ServiceStatusError
+	// ServiceStatusReady is a status of running service which is ready to serve,
+	// see ServiceConfig.ReadinessSignal.
+	ServiceStatusReady
+	// ServiceStatusDegraded is a status of running service which reported
+	// that it's impaired with Reporter.Degraded.
+	ServiceStatusDegraded
+	// ServiceStatusPaused is a status of intentionally suspended service.
+	ServiceStatusPaused
+	// ServiceStatusCompleted is a status of successfully finished job,
+	// see ServiceConfig.Job.
+	ServiceStatusCompleted
 )
 
+// Up reports whether service is running, ready or degraded.
+func (s ServiceStatus) Up() bool {
+	return s == ServiceStatusRunning || s == ServiceStatusReady || s == ServiceStatusDegraded
+}
+
+// MarshalText encodes service status as its name.
+func (s ServiceStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes service status from its name.
+func (s *ServiceStatus) UnmarshalText(text []byte) error {
+	for i := 0; i < len(_ServiceStatus_index)-1; i++ {
+		if st := ServiceStatus(i); st.String() == string(text) {
+			*s = st
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown service status %q", text)
+}
+
 // ServiceRestartPolicy represents rules for service restart on runtime errors.
 type ServiceRestartPolicy struct {
 	// RestartOnFailure indicates that service should be restarted on failure.
@@ -48,15 +130,97 @@ var DefaultRestartPolicy = ServiceRestartPolicy{
 	RestartDelay:     time.Millisecond * 100,
 }
 
+// DefaultHealthCheckInterval is a default interval of service health check.
+const DefaultHealthCheckInterval = 10 * time.Second
+
+// ServiceCriticality defines how service failures affect overall application health.
+type ServiceCriticality int
+
+const (
+	// ServiceCritical (default) service failure makes the application unhealthy.
+	ServiceCritical ServiceCriticality = iota
+	// ServiceOptional service failure makes the application degraded, but still healthy.
+	ServiceOptional
+)
+
+func (c ServiceCriticality) String() string {
+	switch c {
+	case ServiceCritical:
+		return "critical"
+	case ServiceOptional:
+		return "optional"
+	default:
+		return "ServiceCriticality(" + strconv.Itoa(int(c)) + ")"
+	}
+}
+
 // ServiceConfig represents lifecycle service configuration.
 type ServiceConfig struct {
 	// StartupHook is a hook that is called when service is started.
 	StartupHook StartupHook
 	// ShutdownHook is a hook that is called when service is stopped.
 	ShutdownHook ShutdownHook
+	// PauseHook is an optional hook to suspend running service without stopping it.
+	PauseHook PauseHook
+	// ResumeHook is an optional hook to resume paused service.
+	ResumeHook ResumeHook
+	// Job makes the service one-shot job: job is started after startup hook
+	// and service is completed when it returns, or failed if it returns error.
+	// Failed jobs are restarted according to restart policy, see JobService.
+	Job Job
+	// Run is a function of run-style service, see RunService.
+	Run RunFunc
+	// ReloadHook is an optional hook to reload running service without restart.
+	ReloadHook ReloadHook
+	// HealthCheck is an optional health check called periodically while service is up,
+	// failed check makes service degraded until the next successful check.
+	HealthCheck HealthCheck
+	// HealthCheckInterval is an interval and timeout of health check,
+	// DefaultHealthCheckInterval is used if it's not set.
+	HealthCheckInterval time.Duration
 
 	// Name of the service.
 	Name string
 	// RestartPolicy is a restart policy for service.
 	RestartPolicy ServiceRestartPolicy
+	// Criticality of the service, services are critical by default.
+	Criticality ServiceCriticality
+	// DependsOn is a list of names of services this service depends on,
+	// it's used for startup critical path analysis.
+	DependsOn []string
+	// WaitDependencies delays service startup until services from DependsOn are ready
+	// or degraded, or jobs are completed, dependencies should be registered before the service.
+	WaitDependencies bool
+	// ReadinessSignal indicates that service reports readiness with Reporter.Ready,
+	// otherwise service is ready as soon as its startup hook returns.
+	ReadinessSignal bool
+	// Middlewares of service hooks, the first one is the outermost.
+	Middlewares []Middleware
+	// ErrorClassifier classifies runtime errors which are not marked
+	// with Permanent or Ignorable, errors are transient if it's not set.
+	ErrorClassifier ErrorClassifier
+}
+
+// RunService creates config of run-style service with default restart policy.
+// The function is started after startup hook, if any, and runs in background.
+// Its context is cancelled when service is stopped and Stop waits until it returns.
+// Function returning nil before stop is a clean exit and service becomes stopped,
+// returned error is a runtime error handled by restart policy.
+func RunService(name string, run RunFunc) ServiceConfig {
+	return ServiceConfig{
+		Name:          name,
+		Run:           run,
+		RestartPolicy: DefaultRestartPolicy,
+	}
+}
+
+// JobService creates config of one-shot job with default restart policy.
+// The job is started after startup hook, if any, and service is completed
+// when it returns nil, returned error is handled by restart policy.
+func JobService(name string, job Job) ServiceConfig {
+	return ServiceConfig{
+		Name:          name,
+		Job:           job,
+		RestartPolicy: DefaultRestartPolicy,
+	}
 }